@@ -17,15 +17,18 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/thekubeworld/k8devel/pkg/apply"
 	"github.com/thekubeworld/k8devel/pkg/client"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -35,13 +38,25 @@ func main() {
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
 
-	yamlInput, err := ioutil.ReadFile("file.yaml")
+	// file.yaml or a directory with YAML and JSON files
+	docs, err := manifest.ReadDir("file.yaml")
 	if err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	output := apply.YAML(&c, yamlInput)
-	for _, i := range output {
-		fmt.Println(i)
+	// Server-side apply, run it again and the objects
+	// are reported as unchanged. Returns when all objects
+	// are ready.
+	results := apply.Documents(ctx, &c, docs, apply.Options{
+		ServerSide:   true,
+		FieldManager: "k8devel-example",
+		Wait:         true,
+		WaitTimeout:  2 * time.Minute,
+	})
+	results.WriteTable(os.Stdout)
+	if err := results.Err(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
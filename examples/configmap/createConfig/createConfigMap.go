@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -26,6 +27,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	e := emoji.LoadEmojis()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
@@ -43,7 +45,7 @@ func main() {
 		ConfigValue: "address-pools:\n- name: default\n  protocol: layer2\n  addresses:\n  - 172.17.255.1-172.17.255.250 \n",
 	}
 
-	err := configmap.Create(&c, &cfgmap)
+	err := configmap.Create(ctx, &c, &cfgmap)
 	if err != nil {
 		fmt.Printf("%s %s\n", emoji.Show(e.CrossMark), err)
 		os.Exit(1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -26,6 +27,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	e := emoji.LoadEmojis()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
@@ -36,7 +38,7 @@ func main() {
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
 
-	configMapList, err := configmap.ListAll(&c)
+	configMapList, err := configmap.ListAll(ctx, &c)
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
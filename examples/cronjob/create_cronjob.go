@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	newCronJob := "mycronjob" // Put here the new cronjob name
 	namespace := "default"    // Put here the namespace name
 
@@ -56,7 +58,7 @@ func main() {
 	j.Pod.Image = "ubuntu:latest"
 	j.Pod.Command = command
 
-	err := cronjob.Create(&c, &j)
+	err := cronjob.Create(ctx, &c, &j)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -17,15 +17,19 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/delete"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -40,8 +44,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	output := delete.YAML(&c, yamlInput)
-	for _, i := range output {
-		fmt.Println(i)
+	// Dependents are deleted before the objects and it
+	// returns when all objects are gone
+	results := delete.YAMLWithOptions(ctx, &c, yamlInput, delete.Options{
+		PropagationPolicy: metav1.DeletePropagationForeground,
+		Wait:              true,
+		WaitTimeout:       2 * time.Minute,
+	})
+	results.WriteTable(os.Stdout)
+	if err := results.Err(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
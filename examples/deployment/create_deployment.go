@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	newDeployment := "newdeployment" // Put here the new deployment name
 	namespace := "default"           // Put here the namespace name
 
@@ -51,7 +53,7 @@ func main() {
 	d.Pod.ContainerPortProtocol = "TCP"
 	d.Pod.ContainerPort = 80
 
-	err := deployment.Create(&c, &d)
+	err := deployment.Create(ctx, &c, &d)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -0,0 +1,57 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/thekubeworld/k8devel/pkg/apply"
+	"github.com/thekubeworld/k8devel/pkg/client"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+)
+
+func main() {
+	ctx := context.Background()
+	c := client.Client{}
+	c.NumberMaxOfAttemptsPerTask = 10
+	c.TimeoutTaskInSec = 2
+
+	// Connect to cluster from:
+	//      - $HOME/kubeconfig (Linux)
+	//      - os.Getenv("USERPROFILE") (Windows)
+	c.Connect()
+
+	docs, err := manifest.ReadFile("../apply/file.yaml")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Nothing is changed in the cluster, the API Server
+	// provides the objects as they would be after apply
+	results := apply.DiffDocuments(ctx, &c, docs, apply.Options{
+		FieldManager: "k8devel-example",
+	})
+	results.WriteDiff(os.Stdout)
+	results.WriteTable(os.Stdout)
+	if err := results.Err(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -17,23 +17,27 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/deployment"
 	"github.com/thekubeworld/k8devel/pkg/endpoint"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 	"github.com/thekubeworld/k8devel/pkg/namespace"
 	"github.com/thekubeworld/k8devel/pkg/service"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
 
 func main() {
+	ctx := context.Background()
 	// Initial set
 	c := client.Client{}
 	c.Namespace = "kptesting"
 	c.NumberMaxOfAttemptsPerTask = 5
 	c.TimeoutTaskInSec = 20
+	c.Logger = logger.NewWriter(os.Stdout, logger.LevelInfo)
 
 	// Connect to cluster from:
 	//	- $HOME/kubeconfig (Linux)
@@ -53,7 +57,7 @@ func main() {
 	EndpointName := "kproxy-service" + randStr
 
 	// START: Namespace
-	err = namespace.Create(&c, NamespaceName)
+	err = namespace.Create(ctx, &c, NamespaceName)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -75,7 +79,7 @@ func main() {
 	d.Pod.ContainerPortProtocol = "TCP"
 	d.Pod.ContainerPort = 80
 
-	err = deployment.Create(&c, &d)
+	err = deployment.Create(ctx, &c, &d)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -93,13 +97,13 @@ func main() {
 		ClusterIP:     "",
 		Port:          80,
 	}
-	err = service.CreateClusterIP(&c, &s)
+	err = service.CreateClusterIP(ctx, &c, &s)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
 	}
 
-	IPService, err := service.GetIP(&c, ServiceName, NamespaceName)
+	IPService, err := service.GetIP(ctx, &c, ServiceName, NamespaceName)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -117,21 +121,28 @@ func main() {
 	e.EndpointPort.Port = 80
 	e.EndpointPort.Protocol = "TCP"
 
-	epoint, _ := endpoint.Exists(&c, &e)
+	epoint, _ := endpoint.Exists(ctx, &c, &e)
 	if len(epoint) > 0 {
-		err = endpoint.Patch(&c, &e)
+		err = endpoint.Patch(ctx, &c, &e)
 		if err != nil {
 			fmt.Printf("exiting... failed to update: %s\n", err)
 			os.Exit(1)
 		}
 	} else {
-		err = endpoint.Create(&c, &e)
+		err = endpoint.Create(ctx, &c, &e)
 		if err != nil {
 			fmt.Printf("exiting... failed to create: %s\n", err)
 			os.Exit(1)
 		}
 	}
-	endpoint.Show(&c, EndpointName, c.Namespace)
-	endpoint.List(&c, &e)
+	endpoint.Show(ctx, &c, EndpointName, c.Namespace)
+	epoints, err := endpoint.List(ctx, &c, &e)
+	if err != nil {
+		fmt.Printf("exiting... failed to list: %s\n", err)
+		os.Exit(1)
+	}
+	for _, ep := range epoints.Items {
+		fmt.Printf("Endpoint: %s namespace: %s\n", ep.Name, ep.Namespace)
+	}
 	// END: Endpoint
 }
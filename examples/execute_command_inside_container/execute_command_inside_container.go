@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	containerName := "mytesting" // Put here the Pod name
 	namespace := "default"       // Put here the namespace name
 	cmd := []string{"ls", "-la"} // Put here the command to be executed inside container
@@ -45,9 +47,9 @@ func main() {
 	}
 	// POD Settings
 
-	pod.Create(&c, &p)
+	pod.Create(ctx, &c, &p)
 
-	stdout, _, err := pod.ExecCmd(&c, containerName, namespace, cmd)
+	stdout, _, err := pod.ExecCmd(ctx, &c, containerName, namespace, cmd)
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
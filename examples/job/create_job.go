@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	newJob := "mynewjob"   // Put here the new job name
 	namespace := "default" // Put here the namespace name
 
@@ -49,7 +51,7 @@ func main() {
 	j.Pod.Image = "ubuntu:latest"
 	j.Pod.Command = command
 
-	err := job.Create(&c, &j)
+	err := job.Create(ctx, &c, &j)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
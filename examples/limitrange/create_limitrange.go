@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -86,7 +88,7 @@ func main() {
 	}
 
 	fmt.Printf("creating limit range: %s\n", l.Name)
-	err := limitrange.Create(&c, &l)
+	err := limitrange.Create(ctx, &c, &l)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(-1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -35,7 +37,7 @@ func main() {
 	c.Connect()
 
 	fmt.Printf("Deleting limit range: %s\n", limitRangeName)
-	err := limitrange.Delete(&c, limitRangeNS, limitRangeName)
+	err := limitrange.Delete(ctx, &c, limitRangeNS, limitRangeName)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(-1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -27,6 +28,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 
 	// Connect to cluster from:
@@ -34,7 +36,7 @@ func main() {
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
 
-	err := metallb.Deploy(&c, "v0.9.6")
+	err := metallb.Deploy(ctx, &c, "v0.9.6")
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
@@ -49,7 +51,7 @@ func main() {
 		Key:       "secretkey",
 		Value:     base64Str,
 	}
-	err = metallb.CreateSecret(&c, &metallbSecret)
+	err = metallb.CreateSecret(ctx, &c, &metallbSecret)
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
@@ -65,7 +67,7 @@ func main() {
 		AddressPoolProtocol:  "layer2",
 		AddressPoolAddresses: "172.17.255.1-172.17.255.250",
 	}
-	err = metallb.CreateConfig(&c, &conf)
+	err = metallb.CreateConfig(ctx, &c, &conf)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
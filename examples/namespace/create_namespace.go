@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -26,6 +27,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	randStr, err := util.GenerateRandomString(6, "lower")
 	if err != nil {
 		fmt.Printf("%s\n", err)
@@ -41,7 +43,7 @@ func main() {
 	//      - $HOME/kubeconfig (Linux)
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
-	err = namespace.Create(&c, newNamespace)
+	err = namespace.Create(ctx, &c, newNamespace)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -35,6 +36,7 @@ func contains(s []string, str string) bool {
 }
 
 func main() {
+	ctx := context.Background()
 	nsNames := []string{"default", "kube-node-lease", "kube-public", "kube-system", "metallb-system", "signalfx", "tekton-pipelines", "itpaas"}
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
@@ -45,7 +47,7 @@ func main() {
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
 
-	nsAll, err := namespace.List(&c)
+	nsAll, err := namespace.List(ctx, &c)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -56,7 +58,7 @@ func main() {
 			continue
 		} else {
 			fmt.Printf("Deleting namespace: %s\n", n.ObjectMeta.Name)
-			namespace.Delete(&c, n.ObjectMeta.Name)
+			namespace.Delete(ctx, &c, n.ObjectMeta.Name)
 		}
 	}
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -33,7 +35,7 @@ func main() {
 	//      - $HOME/kubeconfig (Linux)
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
-	nsAll, err := namespace.List(&c)
+	nsAll, err := namespace.List(ctx, &c)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/namespace"
@@ -24,10 +25,10 @@ import (
 	"github.com/thekubeworld/k8devel/pkg/util"
 	"math"
 	"os"
-	//"os/signal"
+	"os/signal"
 	"strconv"
 	"sync"
-	//"syscall"
+	"syscall"
 	"time"
 )
 
@@ -51,11 +52,12 @@ var c = client.Client{}
 // between the creation and the pod is in running state
 //
 // Args:
+//      ctx - context for cancellation and deadline
 //      Client - struct from client module
 //      podName - pod name
 //      nsName - namespace name
 //
-func generatePod(c *client.Client, podName string, nsName string, wg *sync.WaitGroup) {
+func generatePod(ctx context.Context, c *client.Client, podName string, nsName string, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	p := pod.Instance{
@@ -69,13 +71,13 @@ func generatePod(c *client.Client, podName string, nsName string, wg *sync.WaitG
 
 	timeNow := time.Now()
 	fmt.Printf("creating pod %s in namespace %s\n", podName, nsName)
-	err := pod.CreateWaitRunningState(c, &p)
+	err := pod.CreateWaitRunningState(ctx, c, &p)
 	//if err != nil {
 	//	fmt.Printf("%s\n", err)
 	//	os.Exit(1)
 	//}
 
-	lastTime, err := pod.GetLastTimeConditionHappened(c,
+	lastTime, err := pod.GetLastTimeConditionHappened(ctx, c,
 		"Ready",
 		podName,
 		nsName)
@@ -115,14 +117,15 @@ func generatePod(c *client.Client, podName string, nsName string, wg *sync.WaitG
 // createNamespace create a namespace
 //
 // Args:
+//      ctx - context for cancellation and deadline
 //      Client - struct from client module
 //      nsName - namespace name
 //
 // Return:
 // 	error or nil
-func createNamespaces(c *client.Client, nsName string, wgNs *sync.WaitGroup) {
+func createNamespaces(ctx context.Context, c *client.Client, nsName string, wgNs *sync.WaitGroup) {
 	defer wgNs.Done()
-	err := namespace.Create(c, nsName)
+	err := namespace.Create(ctx, c, nsName)
 	if err != nil {
 		fmt.Println("Failed to create namespace...")
 		os.Exit(1)
@@ -144,16 +147,42 @@ func sumResults(sumResult []float64) float64 {
 	return result
 }
 
-func generateNamespaces(wgNs *sync.WaitGroup) {
+func generateNamespaces(ctx context.Context, wgNs *sync.WaitGroup) {
 	nsName := ""
 	for i := 1; i <= numberNamespaces; i++ {
 		nsName, _ = util.GenerateRandomString(6, "lower")
 		wgNs.Add(1)
-		go createNamespaces(&c, nsName, wgNs)
+		go createNamespaces(ctx, &c, nsName, wgNs)
 		nsSlice = append(nsSlice, nsName)
 	}
 }
 
+// printStats prints the API requests made by the client and
+// the time waiting in the client-side rate limiter
+//
+// Args:
+//      Client - struct from client module
+//
+func printStats(c *client.Client) {
+	stats := c.Stats()
+	requests, errors := stats.Total()
+
+	fmt.Printf("\n📊 API requests 📊\n")
+	fmt.Printf("-----------------------------\n")
+	fmt.Printf("Requests: %v Errors: %v\n", requests, errors)
+	fmt.Printf("Waiting for client-side throttling: %v (%v requests)\n",
+		stats.RateLimiterWait.Round(time.Millisecond),
+		stats.RateLimiterWaits)
+	for _, r := range stats.Requests {
+		fmt.Printf("%-8s %-12s count: %-6v errors: %-4v mean latency: %v\n",
+			r.Verb,
+			r.Resource,
+			r.Count,
+			r.Errors,
+			r.Latency.Mean().Round(time.Millisecond))
+	}
+}
+
 // main
 func main() {
 	fmt.Printf("REPORT GENERATED AT: %v\n", time.Now().Format("2006-01-02 3:4:5 PM"))
@@ -165,6 +194,10 @@ func main() {
 	c.QPS = 1000
 	c.Burst = 1000
 
+	// Ctrl-C cancels all the pending API calls
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Connect to cluster from:
 	//      - $HOME/kubeconfig (Linux)
 	//      - os.Getenv("USERPROFILE") (Windows)
@@ -173,7 +206,7 @@ func main() {
 	var wgPods sync.WaitGroup
 	var wgNamespaces sync.WaitGroup
 
-	generateNamespaces(&wgNamespaces)
+	generateNamespaces(ctx, &wgNamespaces)
 	wgNamespaces.Wait()
 	fmt.Printf("created %s namespaces\n", nsSlice)
 
@@ -181,7 +214,7 @@ func main() {
 	for _, nsName := range nsSlice {
 		for j := 1; j <= numberPods; j++ {
 			wgPods.Add(1)
-			go generatePod(&c,
+			go generatePod(ctx, &c,
 				"pod"+strconv.Itoa(j),
 				nsName,
 				&wgPods)
@@ -214,6 +247,8 @@ func main() {
 		fmt.Printf("Hour: %v\n", hour)
 	}
 
+	printStats(&c)
+
 	fmt.Printf("\nCleaning created objects during the tests...\n")
 	cleanup()
 
@@ -222,9 +257,11 @@ func main() {
 }
 
 func cleanup() {
+	// do not reuse the main context, it might be cancelled already
+	ctx := context.Background()
 	for _, n := range nsSlice {
 		fmt.Printf("Deleting %s\n", n)
-		err := namespace.Delete(&c, n)
+		err := namespace.Delete(ctx, &c, n)
 		if err != nil {
 			fmt.Printf("cannot delete namespace %s\n", n)
 			os.Exit(1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/namespace"
@@ -24,7 +25,9 @@ import (
 	"github.com/thekubeworld/k8devel/pkg/util"
 	"math"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 )
 
@@ -45,11 +48,12 @@ var imageSource = "docker.io/nginx"
 // between the creation and the pod is in running state
 //
 // Args:
+//      ctx - context for cancellation and deadline
 //      Client - struct from client module
 //      podName - pod name
 //      nsName - namespace name
 //
-func generatePod(c *client.Client, podName string, nsName string) {
+func generatePod(ctx context.Context, c *client.Client, podName string, nsName string) {
 	p := pod.Instance{
 		Name:            podName,
 		Namespace:       nsName,
@@ -60,13 +64,13 @@ func generatePod(c *client.Client, podName string, nsName string) {
 	}
 
 	timeNow := time.Now()
-	err := pod.Create(c, &p)
+	err := pod.Create(ctx, c, &p)
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
 	}
 
-	lastTime, err := pod.GetLastTimeConditionHappened(c,
+	lastTime, err := pod.GetLastTimeConditionHappened(ctx, c,
 		"Ready",
 		podName,
 		nsName)
@@ -97,13 +101,14 @@ func generatePod(c *client.Client, podName string, nsName string) {
 // createNamespace create a namespace
 //
 // Args:
+//      ctx - context for cancellation and deadline
 //      Client - struct from client module
 //      nsName - namespace name
 //
 // Return:
 // 	error or nil
-func createNamespace(c *client.Client, nsName string) error {
-	err := namespace.Create(c, nsName)
+func createNamespace(ctx context.Context, c *client.Client, nsName string) error {
+	err := namespace.Create(ctx, c, nsName)
 	if err != nil {
 		return err
 	}
@@ -125,6 +130,32 @@ func sumResults(sumResult []float64) float64 {
 	return result
 }
 
+// printStats prints the API requests made by the client and
+// the time waiting in the client-side rate limiter
+//
+// Args:
+//      Client - struct from client module
+//
+func printStats(c *client.Client) {
+	stats := c.Stats()
+	requests, errors := stats.Total()
+
+	fmt.Printf("\n📊 API requests 📊\n")
+	fmt.Printf("-----------------------------\n")
+	fmt.Printf("Requests: %v Errors: %v\n", requests, errors)
+	fmt.Printf("Waiting for client-side throttling: %v (%v requests)\n",
+		stats.RateLimiterWait.Round(time.Millisecond),
+		stats.RateLimiterWaits)
+	for _, r := range stats.Requests {
+		fmt.Printf("%-8s %-12s count: %-6v errors: %-4v mean latency: %v\n",
+			r.Verb,
+			r.Resource,
+			r.Count,
+			r.Errors,
+			r.Latency.Mean().Round(time.Millisecond))
+	}
+}
+
 // main
 func main() {
 	fmt.Printf("REPORT GENERATED AT: %v\n", time.Now().Format("2006-01-02 3:4:5 PM"))
@@ -133,6 +164,10 @@ func main() {
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 1200 // 20 min
 
+	// Ctrl-C cancels all the pending API calls
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Connect to cluster from:
 	//      - $HOME/kubeconfig (Linux)
 	//      - os.Getenv("USERPROFILE") (Windows)
@@ -141,7 +176,7 @@ func main() {
 	nsName := ""
 	for i := 0; i < numberNamespaces; i++ {
 		nsName, _ = util.GenerateRandomString(6, "lower")
-		err := createNamespace(&c, nsName)
+		err := createNamespace(ctx, &c, nsName)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -149,7 +184,7 @@ func main() {
 		fmt.Printf("\nNamespace %s created ✅\n", nsName)
 		fmt.Printf("Creating pods and waiting for running state ⏳")
 		for i := 0; i < numberPods; i++ {
-			generatePod(&c,
+			generatePod(ctx, &c,
 				"pod"+strconv.Itoa(i),
 				nsName)
 		}
@@ -162,7 +197,7 @@ func main() {
 		totalMinutes = 0
 		totalSec = 0
 
-		err = namespace.Delete(&c, nsName)
+		err = namespace.Delete(ctx, &c, nsName)
 		if err != nil {
 			fmt.Println("cannot delete namespace: %s\n", nsName)
 			os.Exit(1)
@@ -177,4 +212,5 @@ func main() {
 	fmt.Printf("Minutes: %v\n", sumResults(sumMin))
 	fmt.Printf("Seconds: %v\n", sumResults(sumSec))
 
+	printStats(&c)
 }
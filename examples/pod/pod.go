@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	containerName := "mytesting" // Put here the Pod name
 	namespace := "default"       // Put here the namespace name
 
@@ -45,7 +47,7 @@ func main() {
 		LabelValue: "foobar",
 	}
 
-	err := pod.Create(&c, &p)
+	err := pod.Create(ctx, &c, &p)
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
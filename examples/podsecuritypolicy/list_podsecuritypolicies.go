@@ -17,18 +17,54 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/thekubeworld/k8devel/pkg/client"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 	"github.com/thekubeworld/k8devel/pkg/podsecuritypolicy"
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
+	c.Logger = logger.NewWriter(os.Stdout, logger.LevelInfo)
 
 	// Connect to cluster from:
 	//      - $HOME/kubeconfig (Linux)
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
-	podsecuritypolicy.ListAllPodSecurityPolicy(&c)
+	psp, err := podsecuritypolicy.ListAllPodSecurityPolicy(ctx, &c)
+	if err != nil {
+		fmt.Printf("exiting... failed to list: %s\n", err)
+		os.Exit(1)
+	}
+
+	for _, p := range psp.Items {
+		fmt.Println("")
+		fmt.Println("Name:", p.Name)
+		fmt.Println("\tPrivileged:", p.Spec.Privileged)
+		if p.Spec.AllowPrivilegeEscalation != nil {
+			fmt.Println("\tAllow PrivilegeEscalation:", *p.Spec.AllowPrivilegeEscalation)
+		} else {
+			fmt.Println("\tAllow PrivilegeEscalation: unset")
+		}
+		fmt.Println("\tRequired DropCapabilities:", p.Spec.RequiredDropCapabilities)
+		fmt.Println("\tDefault AddCapabilities:", p.Spec.DefaultAddCapabilities)
+		fmt.Println("\tAllowed Capabilities:", p.Spec.AllowedCapabilities)
+		fmt.Println("\tVolumes:", p.Spec.Volumes)
+		fmt.Println("\tAllow Host Network:", p.Spec.HostNetwork)
+		fmt.Println("\tAllow Host PID:", p.Spec.HostPID)
+		fmt.Println("\tAllow Host IPC:", p.Spec.HostIPC)
+		fmt.Println("\tRead Only Root Filesystem:", p.Spec.ReadOnlyRootFilesystem)
+		fmt.Println("\tRun As User Strategy:", p.Spec.RunAsUser.Rule)
+		fmt.Println("\tRanges:", podsecuritypolicy.IDRangeToString(p.Spec.RunAsUser.Ranges))
+		fmt.Println("\tFSGroup Strategy:", p.Spec.FSGroup.Rule)
+		fmt.Println("\tRanges:", podsecuritypolicy.IDRangeToString(p.Spec.FSGroup.Ranges))
+		fmt.Println("\tSupplemental Groups Strategy:", p.Spec.SupplementalGroups.Rule)
+		fmt.Println("\tRanges:", podsecuritypolicy.IDRangeToString(p.Spec.SupplementalGroups.Ranges))
+	}
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -28,6 +29,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -50,7 +52,7 @@ func main() {
 		VolumeMode:       "filesystem",
 	}
 
-	pvc, err := pvc.Create(&c, &s)
+	pvc, err := pvc.Create(ctx, &c, &s)
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
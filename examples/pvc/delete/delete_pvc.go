@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -34,7 +36,7 @@ func main() {
 	//      - os.Getenv("USERPROFILE") (Windows)
 	c.Connect()
 
-	err := pvc.Delete(&c, "default", "task-pv-claim")
+	err := pvc.Delete(ctx, &c, "default", "task-pv-claim")
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -45,7 +47,7 @@ func main() {
 	}
 
 	fmt.Printf("creating clusterrolebinding: %s\n", cr.Name)
-	err := clusterrolebinding.Create(&c, &cr)
+	err := clusterrolebinding.Create(ctx, &c, &cr)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(-1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
 	c.TimeoutTaskInSec = 2
@@ -33,7 +35,7 @@ func main() {
 
 	clusterRoleName := "myclusterrole"
 	fmt.Printf("Deleting clusterrole %s\n", clusterRoleName)
-	err := clusterrole.Delete(&c, clusterRoleName)
+	err := clusterrole.Delete(ctx, &c, clusterRoleName)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(-1)
@@ -0,0 +1,74 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	"github.com/thekubeworld/k8devel/pkg/kubeproxy"
+)
+
+func main() {
+	ctx := context.Background()
+
+	// One client per kubeconfig context, example:
+	//	kind create cluster --name iptables
+	//	kind create cluster --name ipvs
+	r := client.Registry{}
+	if len(os.Args) > 1 {
+		r.Contexts = os.Args[1:]
+	}
+	r.Setup = func(cluster string, c *client.Client) {
+		c.NumberMaxOfAttemptsPerTask = 5
+		c.TimeoutTaskInSec = 20
+	}
+
+	// Connect to all contexts from:
+	//	- $HOME/kubeconfig (Linux)
+	//	- os.Getenv("USERPROFILE") (Windows)
+	err := r.Connect()
+	if err != nil {
+		fmt.Printf("%s\n", err)
+		os.Exit(1)
+	}
+
+	results := r.FanOut(ctx, func(ctx context.Context, cluster string, c *client.Client) (interface{}, error) {
+		return kubeproxy.DetectKubeProxyMode(ctx,
+			c,
+			"kube-proxy",
+			"kube-proxy",
+			"kube-system")
+	})
+
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("%s: %s ❌\n", result.Cluster, result.Err)
+			continue
+		}
+		fmt.Printf("%s: kube-proxy mode %s (%v) ✅\n",
+			result.Cluster,
+			result.Value,
+			result.Duration)
+	}
+
+	if results.Err() != nil {
+		os.Exit(1)
+	}
+}
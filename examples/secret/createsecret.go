@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -26,6 +27,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	e := emoji.LoadEmojis()
 	c := client.Client{}
 	c.NumberMaxOfAttemptsPerTask = 10
@@ -44,7 +46,7 @@ func main() {
 		Value:     "mypass",
 	}
 
-	err := secret.Create(&c, &s)
+	err := secret.Create(ctx, &c, &s)
 	if err != nil {
 		fmt.Printf("%s %s\n", emoji.Show(e.CrossMark), err)
 		os.Exit(1)
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,7 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 	newService := "newservice" // Put here the new Service name
 	namespace := "default"     // Put here the namespace name
 
@@ -48,13 +50,14 @@ func main() {
 		ClusterIP:     "",
 		Port:          80,
 	}
-	err := service.CreateClusterIP(&c, &s)
+	err := service.CreateClusterIP(ctx, &c, &s)
 	if err != nil {
 		fmt.Printf("exiting... failed to create: %s\n", err)
 		os.Exit(1)
 	}
 
 	IPService, err := service.GetIP(
+		ctx,
 		&c,
 		newService,
 		namespace)
@@ -0,0 +1,92 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	"github.com/thekubeworld/k8devel/pkg/emoji"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/serviceaccount"
+)
+
+func main() {
+	ctx := context.Background()
+	e := emoji.LoadEmojis()
+	c := client.Client{}
+	c.NumberMaxOfAttemptsPerTask = 10
+	c.TimeoutTaskInSec = 2
+
+	// Connect to cluster from:
+	//      - $HOME/kubeconfig (Linux)
+	//      - os.Getenv("USERPROFILE") (Windows)
+	c.Connect()
+
+	s := serviceaccount.Instance{
+		Name:      "myserviceaccount",
+		Namespace: "default",
+	}
+
+	err := serviceaccount.Create(ctx, &c, &s)
+	if err != nil && !errors.Is(err, k8errors.ErrAlreadyExists) {
+		fmt.Printf("%s %s\n", emoji.Show(e.CrossMark), err)
+		os.Exit(1)
+	}
+
+	// Impersonation, the kubeconfig user must be allowed to
+	// impersonate (i.e. cluster-admin)
+	impersonated, err := c.AsServiceAccount(s.Namespace, s.Name)
+	if err != nil {
+		fmt.Printf("%s %s\n", emoji.Show(e.CrossMark), err)
+		os.Exit(1)
+	}
+	listSecrets(ctx, impersonated, "impersonating", s.Namespace,
+		client.ServiceAccountUsername(s.Namespace, s.Name))
+
+	// Token from TokenRequest API, no impersonation involved
+	authenticated, err := serviceaccount.NewClient(ctx, &c, s.Namespace, s.Name)
+	if err != nil {
+		fmt.Printf("%s %s\n", emoji.Show(e.CrossMark), err)
+		os.Exit(1)
+	}
+	listSecrets(ctx, authenticated, "token", s.Namespace,
+		client.ServiceAccountUsername(s.Namespace, s.Name))
+}
+
+// listSecrets will report if the client can list secrets, a new
+// serviceaccount is expected to be forbidden until a RoleBinding
+// or ClusterRoleBinding grants it
+func listSecrets(ctx context.Context, c *client.Client, via string, namespace string, user string) {
+	e := emoji.LoadEmojis()
+	_, err := c.Clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	err = k8errors.Wrap("list secrets", err)
+	switch {
+	case errors.Is(err, k8errors.ErrForbidden):
+		fmt.Printf("%s %s: %s is forbidden to list secrets\n",
+			emoji.Show(e.Rocket), via, user)
+	case err != nil:
+		fmt.Printf("%s %s: %s\n", emoji.Show(e.CrossMark), via, err)
+	default:
+		fmt.Printf("%s %s: allowed to list secrets\n", emoji.Show(e.Rocket), via)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/thekubeworld/k8devel/pkg/apply"
+	"github.com/thekubeworld/k8devel/pkg/client"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+)
+
+func main() {
+	ctx := context.Background()
+	c := client.Client{}
+	c.NumberMaxOfAttemptsPerTask = 10
+	c.TimeoutTaskInSec = 2
+
+	// Connect to cluster from:
+	//      - $HOME/kubeconfig (Linux)
+	//      - os.Getenv("USERPROFILE") (Windows)
+	c.Connect()
+
+	// The namespace gets a random name in the template,
+	// the replicas come from the values
+	t := manifest.Template{
+		Values: map[string]interface{}{
+			"replicas": 2,
+			"image":    "nginx:1.21",
+		},
+		Funcs: apply.TemplateFuncs(ctx, &c),
+	}
+	docs, err := t.ReadFile("template.yaml")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	results := apply.Documents(ctx, &c, docs, apply.Options{
+		ServerSide:   true,
+		FieldManager: "k8devel-example",
+	})
+	results.WriteTable(os.Stdout)
+	if err := results.Err(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
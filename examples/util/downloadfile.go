@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -24,9 +25,10 @@ import (
 )
 
 func main() {
+	ctx := context.Background()
 
 	url := "https://raw.githubusercontent.com/metallb/metallb/v0.9.6/manifests/metallb.yaml"
-	ret, err := util.DownloadFile(url)
+	ret, err := util.DownloadFile(ctx, url)
 	if err != nil {
 		fmt.Printf("%s\n", err)
 		os.Exit(1)
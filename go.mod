@@ -3,7 +3,14 @@ module github.com/thekubeworld/k8devel
 go 1.16
 
 require (
+	github.com/evanphx/json-patch v4.11.0+incompatible
+	github.com/go-logr/logr v0.4.0
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/prometheus/client_golang v1.11.0
+	github.com/prometheus/common v0.26.0
 	k8s.io/api v0.22.2
 	k8s.io/apimachinery v0.22.2
 	k8s.io/client-go v0.22.2
+	k8s.io/klog/v2 v2.9.0
+	sigs.k8s.io/yaml v1.2.0
 )
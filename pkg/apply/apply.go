@@ -17,228 +17,388 @@ limitations under the License.
 */
 
 import (
-	"bytes"
 	"context"
-	"fmt"
+	"strings"
+	"time"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
-	appsv1 "k8s.io/api/apps/v1"
-	v1 "k8s.io/api/core/v1"
-	v1beta1 "k8s.io/api/policy/v1beta1"
-	rbacv1 "k8s.io/api/rbac/v1"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-const yamlDelimiter = "---"
+// DefaultFieldManager is the field manager for server-side apply
+// when Options.FieldManager is not set
+const DefaultFieldManager = "k8devel"
 
-// decode will Decode data to object
+// Options controls how the objects are sent to the API Server
+type Options struct {
+	// ServerSide uses server-side apply instead of create, the
+	// same manifest can be applied repeatedly and converges
+	ServerSide bool
+
+	// FieldManager owns the fields applied, DefaultFieldManager
+	// when empty
+	FieldManager string
+
+	// Force takes ownership of fields owned by other managers
+	// instead of failing with a conflict (server-side only)
+	Force bool
+
+	// Wait for the objects applied to be ready: Deployments,
+	// DaemonSets and StatefulSets rolled out, Jobs complete, Pods
+	// running, Services with endpoints, PVCs bound and CRDs
+	// established. Objects never ready are reported with
+	// ErrNotReady, see Results.NotReady(). Not done in dry-run.
+	Wait bool
+
+	// WaitTimeout is the time for all objects to be ready,
+	// DefaultWaitTimeout when zero
+	WaitTimeout time.Duration
+
+	// Inventory names the set of objects applied together, the
+	// objects get InventoryLabel and are recorded in the ConfigMap
+	// InventoryPrefix+Inventory. Must be a DNS label.
+	Inventory string
+
+	// InventoryNamespace is the namespace of the inventory
+	// ConfigMap, the client Namespace (or default) when empty
+	InventoryNamespace string
+
+	// Prune deletes the objects of the Inventory applied before
+	// and not in the manifest anymore. Listed without deleting in
+	// dry-run. Skipped when a document fails. Requires ServerSide,
+	// the documents fail with ErrInvalidSpec otherwise.
+	Prune bool
+
+	// Atomic rolls back when a document fails (or an object is
+	// not ready with Wait): objects created are deleted and
+	// objects changed are restored, in the reverse order. The
+	// documents after the failed one are ActionSkipped and the
+	// objects aren't waited. Objects that can't be rolled back
+	// keep the error. Not done in dry-run.
+	Atomic bool
+
+	// Template renders the manifest of YAMLWithOptions before
+	// it's decoded, see manifest.Template. TemplateFuncs() are
+	// available.
+	Template *manifest.Template
+
+	// Overlay changes the objects before apply (patches,
+	// namespace, labels, images), see manifest.Overlay
+	Overlay *manifest.Overlay
+}
+
+// Result is the outcome of a document, see manifest.Result
+type Result = manifest.Result
+
+// Action is what happened to an object, see manifest.Action
+type Action = manifest.Action
+
+// Actions reported by apply
+const (
+	ActionCreated    = manifest.ActionCreated
+	ActionConfigured = manifest.ActionConfigured
+	ActionUnchanged  = manifest.ActionUnchanged
+	ActionPruned     = manifest.ActionPruned
+	ActionRolledBack = manifest.ActionRolledBack
+	ActionSkipped    = manifest.ActionSkipped
+	ActionFailed     = manifest.ActionFailed
+)
+
+// YAML will go by the read object and create it via API
 //
 // Args:
-//     - date []byte
+//	- context for cancellation and deadline
+//	- client struct
+//	- yamlInput []bytes
 //
 // Returns:
-//     runtime.Object or error
+//	- one line per object, i.e. "namespace foo created"
 //
-func decode(data []byte) (runtime.Object, error) {
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-	obj, _, err := decode(data, nil, nil)
-	return obj, err
+func YAML(ctx context.Context, c *client.Client, yamlInput []byte) []string {
+	var output []string
+	for _, result := range YAMLWithOptions(ctx, c, yamlInput, Options{}) {
+		output = append(output, result.String())
+	}
+	return output
 }
 
-// YAML will go by the read object and create it via API
+// YAMLWithOptions will create or server-side apply (see Options)
+// the objects from the YAML or JSON manifest, any kind served by the
+// cluster (custom resources included) is supported. Namespaced
+// objects without namespace go to the client Namespace. With
+// Options.Template the manifest is rendered first.
 //
 // Args:
-//      - client struct
+//	- context for cancellation and deadline
+//	- client struct
 //	- yamlInput []bytes
+//	- Options
 //
 // Returns:
-//	- None
+//	- Result per document in the manifest order, see Results.Err()
 //
-// TODO: Can we simplify the switch?
-func YAML(c *client.Client, yamlInput []byte) []string {
-	var output []string
-	yamlFiles := bytes.Split(yamlInput, []byte(yamlDelimiter))
+func YAMLWithOptions(ctx context.Context,
+	c *client.Client,
+	yamlInput []byte,
+	opts Options) manifest.Results {
+
+	if opts.Template == nil {
+		return Documents(ctx, c, manifest.Parse(yamlInput), opts)
+	}
+
+	docs, err := render(ctx, c, yamlInput, *opts.Template)
+	if err != nil {
+		return manifest.Results{{Action: ActionFailed, Err: err}}
+	}
+	return Documents(ctx, c, docs, opts)
+}
+
+// Documents will create or server-side apply (see Options) the
+// objects read by the manifest package, i.e. from files or
+// directories with manifest.ReadDir(). Objects are applied in
+// the order of manifest.KindOrder and CustomResourceDefinitions
+// are waited to be Established before the next kinds. With
+// Options.Inventory the objects are recorded (and pruned) and
+// with Options.Wait they are waited to be ready. With
+// Options.Atomic the changes are rolled back when a document
+// fails. Options.Overlay is applied to copies of the objects.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- slice of manifest.Document
+//	- Options
+//
+// Returns:
+//	- Result per document in the manifest order, followed by the
+//	  objects pruned and the inventory, see Results.Err()
+//
+func Documents(ctx context.Context,
+	c *client.Client,
+	docs []manifest.Document,
+	opts Options) manifest.Results {
+
+	if opts.Overlay != nil {
+		overlaid, err := opts.Overlay.Apply(docs)
+		if err != nil {
+			return failAll(docs, err)
+		}
+		docs = overlaid
+	}
+
+	if len(opts.Inventory) > 0 {
+		if err := validateInventory(opts); err != nil {
+			return failAll(docs, err)
+		}
+		setInventoryLabel(docs, opts.Inventory)
+	}
 
-	for _, f := range yamlFiles {
-		if len(f) == 0 || string(f) == "\n" {
+	ordered := make([]manifest.Document, len(docs))
+	copy(ordered, docs)
+	manifest.Sort(ordered)
+
+	atomic := opts.Atomic && !c.IsDryRun()
+	aborted := false // a document failed with Atomic
+	var results manifest.Results
+	var crds []int // CRDs applied and not waited yet
+	objects := map[int]*unstructured.Unstructured{}
+	var changes []change // objects created or changed, in order
+	for _, doc := range ordered {
+		if !aborted && doc.Err == nil && len(crds) > 0 && !isCRD(doc.Object) {
+			waitForCRDs(ctx, c, results, crds)
+			crds = nil
+			aborted = atomic && results.Err() != nil
+		}
+		switch {
+		case aborted:
+			result := doc.Result()
+			result.Action = ActionSkipped
+			result.Err = nil
+			results = append(results, result)
+			continue
+		case doc.Err != nil:
+			results = append(results, doc.Result())
+			aborted = atomic
 			continue
 		}
 
-		obj, err := decode(f)
+		result, previous := applyObject(ctx, c, doc, opts)
+		results = append(results, result)
+		if result.Err == nil {
+			objects[doc.Index] = doc.Object
+		}
+		if result.Action == ActionCreated || result.Action == ActionConfigured {
+			changes = append(changes, change{
+				index:    doc.Index,
+				obj:      doc.Object,
+				previous: previous,
+			})
+		}
+		// not created in dry-run
+		if isCRD(doc.Object) && result.Err == nil && !c.IsDryRun() {
+			crds = append(crds, len(results)-1)
+		}
+		aborted = atomic && result.Err != nil
+	}
+	if !aborted {
+		waitForCRDs(ctx, c, results, crds)
+	}
+
+	results.ByIndex()
+
+	// not created in dry-run, rolled back anyway when aborted
+	if opts.Wait && !c.IsDryRun() && !aborted {
+		timeout := opts.WaitTimeout
+		if timeout == 0 {
+			timeout = DefaultWaitTimeout
+		}
+		waitForReady(ctx, c, results, objects, timeout)
+	}
+
+	if atomic && results.Err() != nil {
+		rollback(ctx, c, results, changes)
+	}
+
+	if len(opts.Inventory) > 0 {
+		results = updateInventory(ctx, c, opts, ordered, results)
+	}
+	return results
+}
+
+// failAll will provide the documents as failed with the error
+func failAll(docs []manifest.Document, err error) manifest.Results {
+	var results manifest.Results
+	for _, doc := range docs {
+		result := doc.Result()
+		result.Action = ActionFailed
+		result.Err = err
+		results = append(results, result)
+	}
+	return results
+}
+
+// applyObject will create or server-side apply the object, any
+// kind served by the cluster is supported. The object before apply
+// is provided to roll back, nil when it didn't exist.
+func applyObject(ctx context.Context,
+	c *client.Client,
+	doc manifest.Document,
+	opts Options) (Result, *unstructured.Unstructured) {
+
+	obj := doc.Object
+	result := doc.Result()
+	result.DryRun = c.DryRun
+
+	ri, _, err := c.ResourceFor(obj)
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+		return result, nil
+	}
+	result.Namespace = obj.GetNamespace()
+
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      result.GVK.Kind,
+		Namespace: result.Namespace,
+		Name:      result.Name,
+		Object:    obj,
+	}
+
+	if !opts.ServerSide {
+		err = c.Do(ctx, operation, func(ctx context.Context) error {
+			_, err := ri.Create(ctx, obj, c.CreateOptions())
+			return err
+		})
 		if err != nil {
-			continue
+			result.Action = ActionFailed
+			result.Err = err
+			return result, nil
 		}
+		result.Action = ActionCreated
+		return result, nil
+	}
+
+	// The manifest is sent as written, only the fields from it
+	// are owned by the field manager
+	patch, err := obj.MarshalJSON()
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = k8errors.New(k8errors.ErrInvalidSpec, operation.String(), "%s", err)
+		return result, nil
+	}
+
+	current, err := ri.Get(ctx, result.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		current = nil
+	case err != nil:
+		result.Action = ActionFailed
+		result.Err = k8errors.Wrap("get "+strings.ToLower(result.GVK.Kind)+" "+result.Name, err)
+		return result, nil
+	}
+
+	fieldManager := opts.FieldManager
+	if len(fieldManager) == 0 {
+		fieldManager = DefaultFieldManager
+	}
+	patchOptions := c.PatchOptions()
+	patchOptions.FieldManager = fieldManager
+	patchOptions.Force = &opts.Force
 
-		switch obj.(type) {
-		case *v1.ServiceAccount:
-			// If namespace not declared, use default
-			namespace := ""
-			if len(obj.(*v1.ServiceAccount).Namespace) == 0 {
-				namespace = "default"
-			} else {
-				namespace = obj.(*v1.ServiceAccount).Namespace
-			}
-			_, err = c.Clientset.CoreV1().ServiceAccounts(namespace).Create(
-				context.TODO(),
-				obj.(*v1.ServiceAccount),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("service ",
-						obj.(*v1.ServiceAccount).Name,
-						" created"))
-			}
-		case *v1.Namespace:
-			_, err = c.Clientset.CoreV1().Namespaces().Create(
-				context.TODO(),
-				obj.(*v1.Namespace),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("namespace ",
-						obj.(*v1.Namespace).Name,
-						" created"))
-			}
-		case *v1.ConfigMap:
-			_, err = c.Clientset.CoreV1().ConfigMaps(obj.(*v1.ConfigMap).Namespace).Create(
-				context.TODO(),
-				obj.(*v1.ConfigMap),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("configmap ",
-						obj.(*v1.ConfigMap).Name,
-						" created"))
-			}
-		case *appsv1.Deployment:
-			_, err = c.Clientset.AppsV1().Deployments(obj.(*appsv1.Deployment).Namespace).Create(
-				context.TODO(),
-				obj.(*appsv1.Deployment),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("deployment ",
-						obj.(*appsv1.Deployment).Name,
-						" created"))
-			}
-		case *appsv1.DaemonSet:
-			_, err = c.Clientset.AppsV1().DaemonSets(obj.(*appsv1.DaemonSet).Namespace).Create(
-				context.TODO(),
-				obj.(*appsv1.DaemonSet),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("daemonset ",
-						obj.(*appsv1.DaemonSet).Name,
-						" created"))
-			}
-		case *appsv1.StatefulSet:
-			_, err = c.Clientset.AppsV1().StatefulSets(obj.(*appsv1.StatefulSet).Namespace).Create(
-				context.TODO(),
-				obj.(*appsv1.StatefulSet),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("statefulset ",
-						obj.(*appsv1.StatefulSet).Name,
-						" created"))
-			}
-		case *v1beta1.PodSecurityPolicy:
-			_, err = c.Clientset.PolicyV1beta1().PodSecurityPolicies().Create(
-				context.TODO(),
-				obj.(*v1beta1.PodSecurityPolicy),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("podsecuritypolicy ",
-						obj.(*v1beta1.PodSecurityPolicy).Name,
-						" created"))
-			}
-		case *rbacv1.ClusterRole:
-			_, err = c.Clientset.RbacV1().ClusterRoles().Create(
-				context.TODO(),
-				obj.(*rbacv1.ClusterRole),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("clusterrole ",
-						obj.(*rbacv1.ClusterRole).Name,
-						" created"))
-			}
-		case *rbacv1.RoleBinding:
-			_, err = c.Clientset.RbacV1().RoleBindings(obj.(*rbacv1.RoleBinding).Namespace).Create(
-				context.TODO(),
-				obj.(*rbacv1.RoleBinding),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("rolebinding ",
-						obj.(*rbacv1.RoleBinding).Name,
-						" created"))
-			}
-		case *rbacv1.ClusterRoleBinding:
-			_, err = c.Clientset.RbacV1().ClusterRoleBindings().Create(
-				context.TODO(),
-				obj.(*rbacv1.ClusterRoleBinding),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("clusterrolebinding ",
-						obj.(*rbacv1.ClusterRoleBinding).Name,
-						" created"))
-			}
-		case *rbacv1.Role:
-			_, err = c.Clientset.RbacV1().Roles(obj.(*rbacv1.Role).Namespace).Create(
-				context.TODO(),
-				obj.(*rbacv1.Role),
-				metav1.CreateOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("role ",
-						obj.(*rbacv1.Role).Name,
-						" created"))
-			}
-		default:
-			output = append(
-				output,
-				"error, unknown object kind for applying, verify yaml provided...")
+	operation.Verb = "apply"
+	var applied *unstructured.Unstructured
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		obj, err := ri.Patch(ctx, result.Name, types.ApplyPatchType, patch, patchOptions)
+		if err == nil {
+			applied = obj
 		}
+		return err
+	})
+	switch {
+	case err != nil:
+		result.Action = ActionFailed
+		result.Err = err
+	case current == nil:
+		result.Action = ActionCreated
+	case applied == nil:
+		// client dry run, nothing sent
+		result.Action = ActionConfigured
+	case unchanged(current, applied):
+		result.Action = ActionUnchanged
+	default:
+		result.Action = ActionConfigured
+	}
+	return result, current
+}
 
+// unchanged will report if the object applied matches the object
+// before, resourceVersion can't be used as it's not increased in
+// server dry run
+func unchanged(before *unstructured.Unstructured, after *unstructured.Unstructured) bool {
+	return equality.Semantic.DeepEqual(comparableFields(before), comparableFields(after))
+}
+
+// comparableFields will provide the object without status and
+// the fields set by the API Server
+func comparableFields(obj *unstructured.Unstructured) map[string]interface{} {
+	u := obj.DeepCopy().Object
+	delete(u, "status")
+	for _, field := range []string{
+		"resourceVersion",
+		"managedFields",
+		"generation",
+		"uid",
+		"creationTimestamp",
+		"selfLink",
+	} {
+		unstructured.RemoveNestedField(u, "metadata", field)
 	}
-	return output
+	return u
 }
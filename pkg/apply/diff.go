@@ -0,0 +1,287 @@
+package apply
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Diff will compare the objects from the YAML or JSON manifest
+// with the live objects, the manifest is rendered with
+// Options.Template like YAMLWithOptions, see DiffDocuments
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- yamlInput []bytes
+//	- Options, Template, Overlay, Inventory, FieldManager and
+//	  Force are used
+//
+// Returns:
+//	- Result per document in the manifest order, with Diff set
+//
+func Diff(ctx context.Context,
+	c *client.Client,
+	yamlInput []byte,
+	opts Options) manifest.Results {
+
+	if opts.Template == nil {
+		return DiffDocuments(ctx, c, manifest.Parse(yamlInput), opts)
+	}
+
+	docs, err := render(ctx, c, yamlInput, *opts.Template)
+	if err != nil {
+		return manifest.Results{{Action: ActionFailed, Err: err}}
+	}
+	return DiffDocuments(ctx, c, docs, opts)
+}
+
+// DiffDocuments will compare every object with the live object,
+// like kubectl diff. The object after apply is provided by the
+// API Server with a server-side apply in dry run, nothing is
+// changed. Fields managed by the API Server (status,
+// resourceVersion, managedFields, ...) are not compared and the
+// values of Secrets are masked. The objects are the ones Documents
+// would send: Options.Overlay is applied, the Options.Inventory
+// label is set and they are compared in the manifest.KindOrder.
+// Wait, Atomic and Prune are not used, the objects to be pruned
+// are not listed.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- slice of manifest.Document
+//	- Options, Overlay, Inventory, FieldManager and Force are
+//	  used
+//
+// Returns:
+//	- Result per document in the manifest order, Action is what
+//	  apply would do and Diff the unified diff (empty when
+//	  unchanged), see Results.WriteDiff()
+//
+func DiffDocuments(ctx context.Context,
+	c *client.Client,
+	docs []manifest.Document,
+	opts Options) manifest.Results {
+
+	if opts.Overlay != nil {
+		overlaid, err := opts.Overlay.Apply(docs)
+		if err != nil {
+			return failAll(docs, err)
+		}
+		docs = overlaid
+	}
+
+	if len(opts.Inventory) > 0 {
+		if err := validateInventory(opts); err != nil {
+			return failAll(docs, err)
+		}
+		setInventoryLabel(docs, opts.Inventory)
+	}
+
+	ordered := make([]manifest.Document, len(docs))
+	copy(ordered, docs)
+	manifest.Sort(ordered)
+
+	var results manifest.Results
+	for _, doc := range ordered {
+		if doc.Err != nil {
+			results = append(results, doc.Result())
+			continue
+		}
+		results = append(results, diffObject(ctx, c, doc, opts))
+	}
+	results.ByIndex()
+	return results
+}
+
+// diffObject will compare the object with the live object
+func diffObject(ctx context.Context,
+	c *client.Client,
+	doc manifest.Document,
+	opts Options) Result {
+
+	obj := doc.Object
+	result := doc.Result()
+
+	ri, _, err := c.ResourceFor(obj)
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+		return result
+	}
+	result.Namespace = obj.GetNamespace()
+	op := "diff " + strings.ToLower(result.GVK.Kind) + " " + result.Object()
+
+	patch, err := obj.MarshalJSON()
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = k8errors.New(k8errors.ErrInvalidSpec, op, "%s", err)
+		return result
+	}
+
+	var live *unstructured.Unstructured
+	err = c.Retry(ctx, op, func(ctx context.Context) error {
+		var err error
+		live, err = ri.Get(ctx, result.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			live = nil
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+		return result
+	}
+
+	fieldManager := opts.FieldManager
+	if len(fieldManager) == 0 {
+		fieldManager = DefaultFieldManager
+	}
+	patchOptions := metav1.PatchOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: fieldManager,
+		Force:        &opts.Force,
+	}
+
+	var merged *unstructured.Unstructured
+	err = c.Retry(ctx, op, func(ctx context.Context) error {
+		var err error
+		merged, err = ri.Patch(ctx, result.Name, types.ApplyPatchType, patch, patchOptions)
+		return err
+	})
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+		return result
+	}
+
+	switch {
+	case live == nil:
+		result.Action = ActionCreated
+	case unchanged(live, merged):
+		result.Action = ActionUnchanged
+		return result
+	default:
+		result.Action = ActionConfigured
+	}
+
+	result.Diff, err = unifiedDiff(result, live, merged)
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = k8errors.Wrap(op, err)
+	}
+	return result
+}
+
+// unifiedDiff will provide the diff between the live object
+// (nil when it doesn't exist) and the object after apply
+func unifiedDiff(r Result, live *unstructured.Unstructured, merged *unstructured.Unstructured) (string, error) {
+	before := map[string]interface{}{}
+	if live != nil {
+		before = comparableFields(live)
+	}
+	after := comparableFields(merged)
+	if r.GVK.Group == "" && r.GVK.Kind == "Secret" {
+		maskSecret(before, after)
+	}
+
+	a, err := toYAML(before)
+	if err != nil {
+		return "", err
+	}
+	b, err := toYAML(after)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.ToLower(r.GVK.Kind) + "/" + r.Object()
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: "live/" + name,
+		ToFile:   "merged/" + name,
+		Context:  3,
+	})
+}
+
+// toYAML will provide the YAML of the object, empty for an
+// object that doesn't exist
+func toYAML(obj map[string]interface{}) (string, error) {
+	if len(obj) == 0 {
+		return "", nil
+	}
+	data, err := yaml.Marshal(obj)
+	return string(data), err
+}
+
+// splitLines will split the text keeping the new lines, no
+// lines for an empty text
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// maskSecret will replace the values of data and stringData,
+// changed values are still visible as changed
+func maskSecret(before map[string]interface{}, after map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		b, _, _ := unstructured.NestedMap(before, field)
+		a, _, _ := unstructured.NestedMap(after, field)
+
+		changed := map[string]bool{}
+		for key, value := range a {
+			if old, ok := b[key]; ok && !equality.Semantic.DeepEqual(old, value) {
+				changed[key] = true
+			}
+		}
+		mask := func(values map[string]interface{}, suffix string) {
+			for key := range values {
+				values[key] = "***"
+				if changed[key] {
+					values[key] = "*** (" + suffix + ")"
+				}
+			}
+		}
+		mask(b, "before")
+		mask(a, "after")
+
+		if b != nil {
+			_ = unstructured.SetNestedMap(before, b, field)
+		}
+		if a != nil {
+			_ = unstructured.SetNestedMap(after, a, field)
+		}
+	}
+}
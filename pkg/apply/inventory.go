@@ -0,0 +1,367 @@
+package apply
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// InventoryLabel is set on every object applied with
+// Options.Inventory, the value is the inventory name
+const InventoryLabel = "k8devel.io/inventory"
+
+// InventoryPrefix is the prefix of the ConfigMap keeping the
+// objects of an inventory, i.e. k8devel-inventory-myapp
+const InventoryPrefix = "k8devel-inventory-"
+
+// validateInventory will check the inventory name, it's used as
+// label value and in the ConfigMap name, and that Prune is used
+// with ServerSide: create fails with AlreadyExists when the
+// manifest is applied again, the documents failed skip Prune
+func validateInventory(opts Options) error {
+	op := "inventory " + opts.Inventory
+	if errs := validation.IsDNS1123Label(opts.Inventory); len(errs) > 0 {
+		return k8errors.New(k8errors.ErrInvalidSpec, op,
+			"%s", strings.Join(errs, ", "))
+	}
+	if opts.Prune && !opts.ServerSide {
+		return k8errors.New(k8errors.ErrInvalidSpec, op,
+			"Prune requires ServerSide")
+	}
+	return nil
+}
+
+// setInventoryLabel will set InventoryLabel on the objects
+func setInventoryLabel(docs []manifest.Document, name string) {
+	for _, doc := range docs {
+		if doc.Object == nil {
+			continue
+		}
+		labels := doc.Object.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[InventoryLabel] = name
+		doc.Object.SetLabels(labels)
+	}
+}
+
+// inventoryKey will provide the key of the object in the
+// inventory ConfigMap: namespace_name_group_kind, the value
+// is the apiVersion
+func inventoryKey(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return obj.GetNamespace() + "_" + obj.GetName() + "_" + gvk.Group + "_" + gvk.Kind
+}
+
+// inventoryObject will provide the object of an inventory entry
+//
+// Args:
+//	- key from inventoryKey
+//	- apiVersion
+//
+// Returns:
+//	- pointer to unstructured.Unstructured, nil when the key is
+//	  not valid
+func inventoryObject(key string, apiVersion string) *unstructured.Unstructured {
+	fields := strings.Split(key, "_")
+	if len(fields) != 4 {
+		return nil
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || gv.Group != fields[2] {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gv.WithKind(fields[3]))
+	obj.SetNamespace(fields[0])
+	obj.SetName(fields[1])
+	return obj
+}
+
+// updateInventory will record the objects applied in the
+// inventory ConfigMap and, with Options.Prune, delete the
+// objects of the inventory that are not in the manifest anymore.
+// Pruning is skipped when a document failed, as its object might
+// not be identified.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- Options
+//	- documents applied
+//	- Results of the documents, in the manifest order
+//
+// Returns:
+//	- Results with the objects pruned and the inventory ConfigMap
+func updateInventory(ctx context.Context,
+	c *client.Client,
+	opts Options,
+	docs []manifest.Document,
+	results manifest.Results) manifest.Results {
+
+	namespace := opts.InventoryNamespace
+	if len(namespace) == 0 {
+		namespace = c.Namespace
+	}
+	if len(namespace) == 0 {
+		namespace = metav1.NamespaceDefault
+	}
+	name := InventoryPrefix + opts.Inventory
+	cms := c.Clientset.CoreV1().ConfigMaps(namespace)
+
+	inventoryResult := Result{
+		Index:     -1,
+		GVK:       v1.SchemeGroupVersion.WithKind("ConfigMap"),
+		Namespace: namespace,
+		Name:      name,
+		DryRun:    c.DryRun,
+	}
+
+	var cm *v1.ConfigMap
+	err := c.Retry(ctx, "get inventory "+namespace+"/"+name, func(ctx context.Context) error {
+		var err error
+		cm, err = cms.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = nil
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		inventoryResult.Action = ActionFailed
+		inventoryResult.Err = err
+		return append(results, inventoryResult)
+	}
+
+	objects := map[string]string{}
+	if cm != nil {
+		for key, apiVersion := range cm.Data {
+			objects[key] = apiVersion
+		}
+	}
+
+	failed := false
+	applied := map[int]bool{}
+	for _, r := range results {
+		if r.Err != nil || r.Action == ActionRolledBack || r.Action == ActionSkipped {
+			failed = true
+			continue
+		}
+		applied[r.Index] = true
+	}
+	inManifest := map[string]bool{}
+	for _, doc := range docs {
+		if doc.Object == nil {
+			continue
+		}
+		key := inventoryKey(doc.Object)
+		inManifest[key] = true
+		if applied[doc.Index] {
+			objects[key] = doc.Object.GetAPIVersion()
+		}
+	}
+
+	switch {
+	case !opts.Prune:
+	case failed:
+		c.Log(logger.LevelWarn, "Prune skipped, documents failed",
+			"inventory", opts.Inventory)
+	default:
+		var pruned manifest.Results
+		pruned, objects = prune(ctx, c, opts.Inventory, objects, inManifest)
+		results = append(results, pruned...)
+	}
+
+	if cm != nil && equality.Semantic.DeepEqual(cm.Data, objects) {
+		inventoryResult.Action = ActionUnchanged
+		return append(results, inventoryResult)
+	}
+
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "ConfigMap",
+		Namespace: namespace,
+		Name:      name,
+	}
+	if cm == nil {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{InventoryLabel: opts.Inventory},
+			},
+		}
+		inventoryResult.Action = ActionCreated
+	} else {
+		operation.Verb = "update"
+		inventoryResult.Action = ActionConfigured
+	}
+	cm.Data = objects
+	operation.Object = cm
+
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		var err error
+		if operation.Verb == "create" {
+			_, err = cms.Create(ctx, cm, c.CreateOptions())
+		} else {
+			_, err = cms.Update(ctx, cm, c.UpdateOptions())
+		}
+		return err
+	})
+	if err != nil {
+		inventoryResult.Action = ActionFailed
+		inventoryResult.Err = err
+	}
+	return append(results, inventoryResult)
+}
+
+// prune will delete the objects of the inventory not in the
+// manifest, in the reverse of manifest.KindOrder. Objects that
+// don't exist anymore or don't have the inventory label (i.e.
+// taken by another inventory) are only removed from the
+// inventory.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- inventory name
+//	- inventory objects, key and apiVersion
+//	- keys of the objects in the manifest
+//
+// Returns:
+//	- Results of the objects deleted and the inventory objects
+//	  left, the ones that failed to be deleted are kept
+func prune(ctx context.Context,
+	c *client.Client,
+	inventory string,
+	objects map[string]string,
+	inManifest map[string]bool) (manifest.Results, map[string]string) {
+
+	var candidates []manifest.Document
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if inManifest[key] {
+			continue
+		}
+		obj := inventoryObject(key, objects[key])
+		if obj == nil {
+			c.Log(logger.LevelWarn, "Invalid inventory entry removed",
+				"inventory", inventory,
+				"key", key)
+			delete(objects, key)
+			continue
+		}
+		candidates = append(candidates, manifest.Document{Index: -1, Object: obj})
+	}
+	manifest.SortForDelete(candidates)
+
+	var results manifest.Results
+	for _, doc := range candidates {
+		key := inventoryKey(doc.Object)
+		result, found := pruneObject(ctx, c, inventory, doc)
+		if found {
+			results = append(results, result)
+		}
+		if result.Err == nil {
+			delete(objects, key)
+		}
+	}
+	return results, objects
+}
+
+// pruneObject will delete the object of the inventory when it
+// still has the inventory label
+//
+// Returns:
+//	- Result and false when there was nothing to delete
+func pruneObject(ctx context.Context,
+	c *client.Client,
+	inventory string,
+	doc manifest.Document) (Result, bool) {
+
+	obj := doc.Object
+	result := doc.Result()
+	result.DryRun = c.DryRun
+
+	ri, _, err := c.ResourceFor(obj)
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+		return result, true
+	}
+	result.Namespace = obj.GetNamespace()
+
+	var live *unstructured.Unstructured
+	op := "get " + strings.ToLower(result.GVK.Kind) + " " + result.Object()
+	err = c.Retry(ctx, op, func(ctx context.Context) error {
+		var err error
+		live, err = ri.Get(ctx, result.Name, metav1.GetOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return result, false
+	}
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+		return result, true
+	}
+	if live.GetLabels()[InventoryLabel] != inventory {
+		c.Log(logger.LevelInfo, "Not pruned, object not in the inventory anymore",
+			"inventory", inventory,
+			"kind", result.GVK.Kind,
+			"namespace", result.Namespace,
+			"name", result.Name)
+		return result, false
+	}
+
+	err = c.Do(ctx, client.Operation{
+		Verb:      "delete",
+		Kind:      result.GVK.Kind,
+		Namespace: result.Namespace,
+		Name:      result.Name,
+	}, func(ctx context.Context) error {
+		return ri.Delete(ctx, result.Name, c.DeleteOptions())
+	})
+	if err != nil {
+		result.Action = ActionFailed
+		result.Err = err
+		return result, true
+	}
+	result.Action = ActionPruned
+	return result, true
+}
@@ -0,0 +1,131 @@
+package apply
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// change is an object created or changed by apply
+type change struct {
+	index    int                        // document Index
+	obj      *unstructured.Unstructured // object applied
+	previous *unstructured.Unstructured // object before, nil when created
+}
+
+// rollback will undo the changes in the reverse order: objects
+// created are deleted and objects changed are restored to the
+// previous version. The results of the objects rolled back are
+// set as ActionRolledBack, the ones that can't be rolled back
+// keep their action with the error.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- Results
+//	- changes, in the order they were applied
+func rollback(ctx context.Context, c *client.Client, results manifest.Results, changes []change) {
+	byIndex := map[int]*Result{}
+	for i := range results {
+		byIndex[results[i].Index] = &results[i]
+	}
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		result, ok := byIndex[changes[i].index]
+		if !ok {
+			continue
+		}
+
+		err := rollbackObject(ctx, c, changes[i])
+		switch {
+		case err == nil:
+			result.Action = ActionRolledBack
+		case result.Err == nil:
+			result.Err = err
+		default:
+			result.Err = fmt.Errorf("%w; %v", result.Err, err)
+		}
+	}
+}
+
+// rollbackObject will delete the object created or restore the
+// previous version of the object changed
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- change
+//
+// Returns:
+//	- nil or error
+func rollbackObject(ctx context.Context, c *client.Client, ch change) error {
+	kind := strings.ToLower(ch.obj.GetKind())
+	name := ch.obj.GetName()
+	if len(ch.obj.GetNamespace()) > 0 {
+		name = ch.obj.GetNamespace() + "/" + name
+	}
+	op := "rollback " + kind + " " + name
+
+	ri, _, err := c.ResourceFor(ch.obj)
+	if err != nil {
+		return k8errors.Wrap(op, err)
+	}
+
+	operation := client.Operation{
+		Verb:      "delete",
+		Kind:      ch.obj.GetKind(),
+		Namespace: ch.obj.GetNamespace(),
+		Name:      ch.obj.GetName(),
+	}
+
+	if ch.previous == nil {
+		err = c.Do(ctx, operation, func(ctx context.Context) error {
+			err := ri.Delete(ctx, ch.obj.GetName(), c.DeleteOptions())
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		})
+		return k8errors.Wrap(op, err)
+	}
+
+	// the previous version replaces the current one, whatever
+	// changed it since
+	previous := ch.previous.DeepCopy()
+	unstructured.RemoveNestedField(previous.Object, "metadata", "managedFields")
+	operation.Verb = "update"
+	operation.Object = previous
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		current, err := ri.Get(ctx, previous.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		previous.SetResourceVersion(current.GetResourceVersion())
+		_, err = ri.Update(ctx, previous, c.UpdateOptions())
+		return err
+	})
+	return k8errors.Wrap(op, err)
+}
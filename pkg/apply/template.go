@@ -0,0 +1,87 @@
+package apply
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"text/template"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	"github.com/thekubeworld/k8devel/pkg/node"
+)
+
+// TemplateFuncs will provide the template functions reading from
+// the cluster, in addition to manifest.TemplateFuncs():
+//	- nodeIPs: IPs of the nodes, see node.GetIPFromNodes()
+//	- nodeIP N: IP of the node N, from 0
+// The nodes are listed once, on first use.
+// Example:
+//	t := manifest.Template{Funcs: apply.TemplateFuncs(ctx, &c)}
+//	docs, err := t.ReadDir("manifests/")
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//
+// Returns:
+//	- template.FuncMap
+func TemplateFuncs(ctx context.Context, c *client.Client) template.FuncMap {
+	var ips []string
+	nodeIPs := func() ([]string, error) {
+		if ips != nil {
+			return ips, nil
+		}
+		var err error
+		ips, err = node.GetIPFromNodes(ctx, c)
+		if err != nil {
+			return nil, k8errors.Wrap("list nodes", err)
+		}
+		return ips, nil
+	}
+
+	return template.FuncMap{
+		"nodeIPs": nodeIPs,
+		"nodeIP": func(n int) (string, error) {
+			ips, err := nodeIPs()
+			if err != nil {
+				return "", err
+			}
+			if n < 0 || n >= len(ips) {
+				return "", k8errors.New(k8errors.ErrInvalidSpec, "node ip",
+					"node %d out of %d nodes", n, len(ips))
+			}
+			return ips[n], nil
+		},
+	}
+}
+
+// render will render the manifest with Options.Template, the
+// functions of TemplateFuncs() are added
+func render(ctx context.Context,
+	c *client.Client,
+	yamlInput []byte,
+	t manifest.Template) ([]manifest.Document, error) {
+
+	funcs := TemplateFuncs(ctx, c)
+	for name, f := range t.Funcs {
+		funcs[name] = f
+	}
+	t.Funcs = funcs
+	return t.Parse(yamlInput)
+}
@@ -0,0 +1,383 @@
+package apply
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultWaitTimeout is the time for all objects to be ready
+// when Options.WaitTimeout is not set
+const DefaultWaitTimeout = 5 * time.Minute
+
+// crdGroupKind is the kind of the CustomResourceDefinitions
+var crdGroupKind = schema.GroupKind{
+	Group: "apiextensions.k8s.io",
+	Kind:  "CustomResourceDefinition",
+}
+
+// readyFunc will report if the object is ready, with the reason
+// when it's not, and an error when it will never be ready
+type readyFunc func(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error)
+
+// readyChecks are the rules by kind, objects of other kinds are
+// ready once applied
+var readyChecks = map[schema.GroupKind]readyFunc{
+	{Group: "apps", Kind: "Deployment"}:  deploymentReady,
+	{Group: "apps", Kind: "DaemonSet"}:   daemonSetReady,
+	{Group: "apps", Kind: "StatefulSet"}: statefulSetReady,
+	{Group: "batch", Kind: "Job"}:        jobReady,
+	{Kind: "Pod"}:                        podReady,
+	{Kind: "Service"}:                    serviceReady,
+	{Kind: "PersistentVolumeClaim"}:      pvcReady,
+	crdGroupKind:                         crdReady,
+}
+
+// isCRD will report if the object is a CustomResourceDefinition
+func isCRD(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind() == crdGroupKind
+}
+
+// waitForCRDs will wait the CustomResourceDefinitions of the
+// results to be Established, so their custom resources can be
+// applied. The results of the CRDs not established are set as
+// failed.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- Results
+//	- position of the CRDs in Results
+func waitForCRDs(ctx context.Context, c *client.Client, results manifest.Results, crds []int) {
+	for _, i := range crds {
+		if err := waitForCRD(ctx, c, results[i]); err != nil {
+			results[i].Action = ActionFailed
+			results[i].Err = err
+		}
+	}
+}
+
+// waitForCRD will poll the CustomResourceDefinition based on the
+// RetryPolicy from the client until it's Established
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- Result of the CRD
+//
+// Returns:
+//	- nil or error
+func waitForCRD(ctx context.Context, c *client.Client, crd Result) error {
+	op := "wait customresourcedefinition " + crd.Name + " established"
+	mapping, err := c.RESTMapping(crd.GVK)
+	if err != nil {
+		return k8errors.Wrap(op, err)
+	}
+	ri := c.Dynamic.Resource(mapping.Resource)
+
+	return c.Poll(ctx, op, func(ctx context.Context) (bool, error) {
+		obj, err := ri.Get(ctx, crd.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		established, _, err := crdReady(ctx, c, obj)
+		if err != nil {
+			return false, k8errors.New(k8errors.ErrInvalidSpec, op, "%s", err)
+		}
+		return established, nil
+	})
+}
+
+// waitForReady will wait the objects applied to be ready, by the
+// rules of readyChecks, until all of them are ready or timeout.
+// The results of objects never ready have Err set (ErrNotReady).
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- Results, in the manifest order
+//	- objects applied by document Index
+//	- timeout for all objects
+func waitForReady(ctx context.Context,
+	c *client.Client,
+	results manifest.Results,
+	objects map[int]*unstructured.Unstructured,
+	timeout time.Duration) {
+
+	op := func(r *Result) string {
+		return "wait " + strings.ToLower(r.GVK.Kind) + " " + r.Object() + " ready"
+	}
+
+	var pending []*Result
+	for i := range results {
+		if _, ok := objects[results[i].Index]; !ok || results[i].Err != nil {
+			continue
+		}
+		if _, ok := readyChecks[results[i].GVK.GroupKind()]; !ok {
+			results[i].Ready = true
+			continue
+		}
+		pending = append(pending, &results[i])
+	}
+
+	notReady := manifest.Wait(ctx, c, pending, timeout, func(ctx context.Context,
+		r *Result,
+		obj *unstructured.Unstructured,
+		err error) (bool, string, error) {
+
+		if err != nil {
+			return false, err.Error(), nil
+		}
+		ready, reason, err := readyChecks[r.GVK.GroupKind()](ctx, c, obj)
+		if err != nil {
+			return false, "", k8errors.New(k8errors.ErrNotReady, op(r), "%s", err)
+		}
+		r.Ready = ready
+		return ready, reason, nil
+	})
+
+	for _, w := range notReady {
+		w.Result.Err = k8errors.New(k8errors.ErrNotReady, op(w.Result),
+			"not ready after %s: %s", timeout, w.Reason)
+	}
+}
+
+// deploymentReady: all replicas updated and available, old
+// replicas terminated
+func deploymentReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	if !observed(obj) {
+		return false, "waiting for the rollout to start", nil
+	}
+	if status, reason, msg := condition(obj, "Progressing"); status == "False" &&
+		reason == "ProgressDeadlineExceeded" {
+		return false, "", fmt.Errorf("rollout failed: %s", msg)
+	}
+
+	replicas := specReplicas(obj)
+	updated := int64Field(obj, "status", "updatedReplicas")
+	total := int64Field(obj, "status", "replicas")
+	available := int64Field(obj, "status", "availableReplicas")
+	switch {
+	case updated < replicas:
+		return false, fmt.Sprintf("%d of %d replicas updated", updated, replicas), nil
+	case total > updated:
+		return false, fmt.Sprintf("%d old replicas pending termination", total-updated), nil
+	case available < updated:
+		return false, fmt.Sprintf("%d of %d updated replicas available", available, updated), nil
+	}
+	return true, "", nil
+}
+
+// daemonSetReady: pods updated and available in all nodes
+// scheduled
+func daemonSetReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	if !observed(obj) {
+		return false, "waiting for the rollout to start", nil
+	}
+
+	desired := int64Field(obj, "status", "desiredNumberScheduled")
+	updated := int64Field(obj, "status", "updatedNumberScheduled")
+	available := int64Field(obj, "status", "numberAvailable")
+	switch {
+	case updated < desired:
+		return false, fmt.Sprintf("%d of %d pods updated", updated, desired), nil
+	case available < desired:
+		return false, fmt.Sprintf("%d of %d pods available", available, desired), nil
+	}
+	return true, "", nil
+}
+
+// statefulSetReady: all replicas ready and in the current
+// revision
+func statefulSetReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	if !observed(obj) {
+		return false, "waiting for the rollout to start", nil
+	}
+
+	replicas := specReplicas(obj)
+	ready := int64Field(obj, "status", "readyReplicas")
+	if ready < replicas {
+		return false, fmt.Sprintf("%d of %d replicas ready", ready, replicas), nil
+	}
+
+	current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+	update, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+	if len(update) > 0 && current != update {
+		updated := int64Field(obj, "status", "updatedReplicas")
+		return false, fmt.Sprintf("%d of %d replicas updated", updated, replicas), nil
+	}
+	return true, "", nil
+}
+
+// jobReady: the job is complete
+func jobReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	if status, _, _ := condition(obj, "Complete"); status == "True" {
+		return true, "", nil
+	}
+	if status, reason, msg := condition(obj, "Failed"); status == "True" {
+		return false, "", fmt.Errorf("job failed: %s: %s", reason, msg)
+	}
+
+	completions, found, _ := unstructured.NestedInt64(obj.Object, "spec", "completions")
+	if !found {
+		completions = 1
+	}
+	succeeded := int64Field(obj, "status", "succeeded")
+	return false, fmt.Sprintf("%d of %d completions succeeded", succeeded, completions), nil
+}
+
+// podReady: the pod is running (or already succeeded)
+func podReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Running", "Succeeded":
+		return true, "", nil
+	case "Failed":
+		reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+		return false, "", fmt.Errorf("pod failed: %s", reason)
+	}
+	return false, "phase " + phase, nil
+}
+
+// serviceReady: the service has endpoints, services without
+// selector or ExternalName are ready once applied
+func serviceReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	selector, _, _ := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if serviceType == "ExternalName" || len(selector) == 0 {
+		return true, "", nil
+	}
+
+	endpoints, err := c.Dynamic.Resource(schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "endpoints",
+	}).Namespace(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, "no endpoints", nil
+	}
+	if err != nil {
+		return false, err.Error(), nil
+	}
+
+	subsets, _, _ := unstructured.NestedSlice(endpoints.Object, "subsets")
+	for _, s := range subsets {
+		subset, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if addresses, ok := subset["addresses"].([]interface{}); ok && len(addresses) > 0 {
+			return true, "", nil
+		}
+	}
+	return false, "no ready endpoints", nil
+}
+
+// pvcReady: the claim is bound
+func pvcReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase == "Bound" {
+		return true, "", nil
+	}
+	return false, "phase " + phase, nil
+}
+
+// crdReady: the CustomResourceDefinition is Established, an
+// error when its names are not accepted
+func crdReady(ctx context.Context,
+	c *client.Client,
+	obj *unstructured.Unstructured) (bool, string, error) {
+
+	if status, _, _ := condition(obj, "Established"); status == "True" {
+		return true, "", nil
+	}
+	if status, _, msg := condition(obj, "NamesAccepted"); status == "False" {
+		return false, "", fmt.Errorf("names not accepted: %s", msg)
+	}
+	return false, "not established", nil
+}
+
+// observed will report if the controller has seen the last
+// generation of the object
+func observed(obj *unstructured.Unstructured) bool {
+	return int64Field(obj, "status", "observedGeneration") >= obj.GetGeneration()
+}
+
+// specReplicas will provide spec.replicas, 1 when not set
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+// int64Field will provide the integer field, 0 when not set
+func int64Field(obj *unstructured.Unstructured, fields ...string) int64 {
+	i, _, _ := unstructured.NestedInt64(obj.Object, fields...)
+	return i
+}
+
+// condition will provide status, reason and message of the
+// condition type from status.conditions, empty when not found
+func condition(obj *unstructured.Unstructured, conditionType string) (string, string, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		message, _ := condition["message"].(string)
+		return status, reason, message
+	}
+	return "", "", ""
+}
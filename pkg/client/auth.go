@@ -0,0 +1,202 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"k8s.io/client-go/dynamic"
+	kubernetes "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmd "k8s.io/client-go/tools/clientcmd"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// Credentials to authenticate against the API Server, when Host
+// is set the kubeconfig is not used at all, otherwise the fields
+// set override the user and cluster from the kubeconfig context
+type Credentials struct {
+	Host            string // API Server URL, i.e. https://127.0.0.1:6443
+	BearerToken     string
+	BearerTokenFile string // read again when it changes (projected tokens)
+	CertFile        string // client certificate
+	CertData        []byte
+	KeyFile         string // client key
+	KeyData         []byte
+	CAFile          string // CA to verify the API Server certificate
+	CAData          []byte
+	Insecure        bool // skip the API Server certificate verification
+}
+
+// restConfig will provide the rest.Config from Host and the
+// credentials, no kubeconfig involved
+func (cred *Credentials) restConfig() *rest.Config {
+	return &rest.Config{
+		Host:            cred.Host,
+		BearerToken:     cred.BearerToken,
+		BearerTokenFile: cred.BearerTokenFile,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure: cred.Insecure,
+			CertFile: cred.CertFile,
+			CertData: cred.CertData,
+			KeyFile:  cred.KeyFile,
+			KeyData:  cred.KeyData,
+			CAFile:   cred.CAFile,
+			CAData:   cred.CAData,
+		},
+	}
+}
+
+// override will set the credentials into the kubeconfig
+// overrides, only the fields set are overridden
+func (cred *Credentials) override(overrides *clientcmd.ConfigOverrides) {
+	overrides.AuthInfo.Token = cred.BearerToken
+	overrides.AuthInfo.TokenFile = cred.BearerTokenFile
+	overrides.AuthInfo.ClientCertificate = cred.CertFile
+	overrides.AuthInfo.ClientCertificateData = cred.CertData
+	overrides.AuthInfo.ClientKey = cred.KeyFile
+	overrides.AuthInfo.ClientKeyData = cred.KeyData
+	overrides.ClusterInfo.CertificateAuthority = cred.CAFile
+	overrides.ClusterInfo.CertificateAuthorityData = cred.CAData
+	overrides.ClusterInfo.InsecureSkipTLSVerify = cred.Insecure
+}
+
+// ServiceAccountUsername will provide the username the API Server
+// assigns to a service account, i.e. for RBAC subjects
+//
+// Args:
+//	- namespace
+//	- service account name
+//
+// Returns:
+//	- string, system:serviceaccount:<namespace>:<name>
+func ServiceAccountUsername(namespace string, name string) string {
+	return "system:serviceaccount:" + namespace + ":" + name
+}
+
+// ServiceAccountGroups will provide the groups the API Server
+// assigns to the service accounts of a namespace
+//
+// Args:
+//	- namespace
+//
+// Returns:
+//	- slice of string
+func ServiceAccountGroups(namespace string) []string {
+	return []string{
+		"system:serviceaccounts",
+		"system:serviceaccounts:" + namespace,
+		"system:authenticated",
+	}
+}
+
+// AsUser will provide a copy of the client impersonating a user,
+// the credentials of the client must be allowed to impersonate.
+// Useful to validate RBAC rules, example:
+//	alice, _ := c.AsUser("alice", "developers")
+//	_, err := alice.Clientset.CoreV1().Secrets("default").List(ctx, metav1.ListOptions{})
+//	errors.Is(k8errors.Wrap("list", err), k8errors.ErrForbidden)
+//
+// Args:
+//	- username
+//	- groups
+//
+// Returns:
+//	- pointer to Client or error
+func (client *Client) AsUser(user string, groups ...string) (*Client, error) {
+	return client.impersonating(rest.ImpersonationConfig{
+		UserName: user,
+		Groups:   groups,
+	})
+}
+
+// AsServiceAccount will provide a copy of the client impersonating
+// a service account, i.e. created by serviceaccount.Create
+//
+// Args:
+//	- namespace
+//	- service account name
+//
+// Returns:
+//	- pointer to Client or error
+func (client *Client) AsServiceAccount(namespace string, name string) (*Client, error) {
+	return client.impersonating(rest.ImpersonationConfig{
+		UserName: ServiceAccountUsername(namespace, name),
+		Groups:   ServiceAccountGroups(namespace),
+	})
+}
+
+// impersonating will provide a copy of the client with the same
+// settings, Restconfig (transport wrappers included) and stats
+// but impersonating someone else
+func (client *Client) impersonating(impersonate rest.ImpersonationConfig) (*Client, error) {
+	op := "impersonate " + impersonate.UserName
+	if client.Restconfig == nil {
+		return nil, k8errors.New(k8errors.ErrNotConnected, op,
+			"Connect() must be called before")
+	}
+
+	restconfig := rest.CopyConfig(client.Restconfig)
+	restconfig.Impersonate = impersonate
+	clientset, err := kubernetes.NewForConfig(client.rateLimited(restconfig))
+	if err != nil {
+		return nil, k8errors.Wrap(op, err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(client.rateLimited(restconfig))
+	if err != nil {
+		return nil, k8errors.Wrap(op, err)
+	}
+
+	// Commands in pods must be impersonated too, other
+	// executors (i.e. fake or replay) are kept
+	executor := client.Executor
+	switch e := executor.(type) {
+	case *SPDYExecutor:
+		executor = &SPDYExecutor{Clientset: clientset, Restconfig: restconfig}
+	case *RecordingExecutor:
+		if _, ok := e.Executor.(*SPDYExecutor); ok {
+			executor = &RecordingExecutor{
+				Executor: &SPDYExecutor{Clientset: clientset, Restconfig: restconfig},
+				Journal:  e.Journal,
+			}
+		}
+	}
+
+	return &Client{
+		Clientset:                  clientset,
+		Restclientset:              clientset,
+		Namespace:                  client.Namespace,
+		Restconfig:                 restconfig,
+		Kubeconfig:                 client.Kubeconfig,
+		TimeoutTaskInSec:           client.TimeoutTaskInSec,
+		NumberMaxOfAttemptsPerTask: client.NumberMaxOfAttemptsPerTask,
+		QPS:                        client.QPS,
+		Burst:                      client.Burst,
+		KubeconfigPaths:            client.KubeconfigPaths,
+		Context:                    client.Context,
+		Credentials:                client.Credentials,
+		Impersonate:                impersonate,
+		Executor:                   executor,
+		RetryPolicy:                client.RetryPolicy,
+		Logger:                     client.Logger,
+		DryRun:                     client.DryRun,
+		Journal:                    client.Journal,
+		HTTPClient:                 client.HTTPClient,
+		Dynamic:                    dynamicClient,
+		RESTMapper:                 client.RESTMapper,
+		metrics:                    client.metrics,
+	}, nil
+}
@@ -17,20 +17,25 @@ limitations under the License.
 */
 
 import (
-	"os"
-	"path/filepath"
+	"net/http"
+	"sync"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	kubernetes "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	clientcmd "k8s.io/client-go/tools/clientcmd"
+
+	"github.com/thekubeworld/k8devel/pkg/logger"
 )
 
 // Client struct contains all info about client
 // to connect into the cluster
 type Client struct {
-	Clientset                  *kubernetes.Clientset
-	Restclientset              *kubernetes.Clientset
-	Namespace                  string
+	Clientset                  kubernetes.Interface
+	Restclientset              kubernetes.Interface
+	Namespace                  string // Overrides the context namespace
 	Restconfig                 *rest.Config
 	Kubeconfig                 clientcmd.ClientConfig
 	TimeoutTaskInSec           int
@@ -38,26 +43,145 @@ type Client struct {
 	QPS                        float32 // Queries per second
 	Burst                      int     // Suddently increase of call to API
 
-	// TODO: remove NumberMaxOfAttemptsPerTask and add some Pool mechanism
-	// for modules that still use it. that
+	// KubeconfigPaths are the kubeconfig files to load. When empty
+	// the standard rules apply: $KUBECONFIG (list of files) or
+	// $HOME/.kube/config. A single path must exist, multiple paths
+	// are merged in order like $KUBECONFIG does.
+	KubeconfigPaths []string
+
+	// Context is the kubeconfig context to use, when empty
+	// the current-context from kubeconfig is used
+	Context string
+
+	// Credentials when Host is set are used instead of kubeconfig,
+	// otherwise the fields set override the kubeconfig context
+	Credentials Credentials
+
+	// Impersonate sends all requests as another user, groups
+	// or service account, see AsUser() and AsServiceAccount()
+	Impersonate rest.ImpersonationConfig
+
+	// Executor runs the commands from pod.ExecCmd, Connect()
+	// sets SPDYExecutor when it's not set
+	Executor Executor
+
+	// RetryPolicy is used by all Create, Delete and Wait operations.
+	// When not set, DefaultRetryPolicy() is used with TimeoutTaskInSec
+	// as the wait per attempt (MaxInterval), NumberMaxOfAttemptsPerTask
+	// as MaxAttempts and their product as MaxElapsedTime
+	RetryPolicy *RetryPolicy
+
+	// Logger receives all messages from the modules, when not set
+	// logger.Default() is used (silent unless logger.SetDefault)
+	Logger logger.Logger
+
+	// DryRun when set to DryRunServer or DryRunClient makes all
+	// Create, Apply and Delete operations not mutate the cluster,
+	// see DryRunOperations() for what would have been done
+	DryRun DryRunMode
+
+	// Journal when set records every API request and response,
+	// download and command executed in pods, see NewReplay() to
+	// serve them back without a cluster
+	Journal *Journal
+
+	// HTTPClient is used for downloads outside the API Server
+	// (i.e. manifests), when not set http.DefaultClient is used
+	HTTPClient *http.Client
+
+	// Dynamic and RESTMapper handle any kind served by the
+	// cluster, including custom resources, see ResourceFor()
+	Dynamic    dynamic.Interface
+	RESTMapper meta.RESTMapper
+
+	dryRunMutex      sync.Mutex
+	dryRunOperations []Operation
+
+	metrics      *metrics
+	configLoaded bool
 }
 
 // Connect will connect to specific Cluster
-// read from kubeconfig
+// read from kubeconfig or in-cluster config
+//
+// The kubeconfig files come from KubeconfigPaths, $KUBECONFIG or
+// $HOME/.kube/config (in this order) and Context selects the
+// kubeconfig context. When no kubeconfig is found and the program
+// runs inside a pod, the in-cluster config is used instead.
+//
+// If Namespace is set it overrides the namespace from the context,
+// otherwise it is filled with the context namespace.
 //
 // Args:
 //
 // Returns:
-//   - Client struct
+//   - Client struct or error
 func (client *Client) Connect() (*Client, error) {
-	// TODO: Users can specify by dynamic the HOME for kubeconfig
-	home, exists := os.LookupEnv("HOME")
-	if !exists {
-		home = os.Getenv("USERPROFILE") // windows
+	_, err := client.KubeClientFromConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Clientset and Restclientset must target the same cluster
+	client.Clientset = client.Restclientset
+	return client, nil
+}
+
+// KubeClientFromConfig will provide the REST interface
+// to cluster, based on KubeconfigPaths, Context, Namespace,
+// Credentials and Impersonate from the Client struct, the
+// config file and environment are loaded (see LoadConfig)
+//
+// Args:
+//
+// Returns:
+//   - Client struct or error
+func (client *Client) KubeClientFromConfig() (*Client, error) {
+	var err error
+
+	// k8devel.yaml and K8DEVEL_* override the fields set in code
+	if !client.configLoaded {
+		if err = client.LoadConfig(); err != nil {
+			return nil, err
+		}
 	}
 
-	configPath := filepath.Join(home, ".kube", "config")
-	config, err := clientcmd.BuildConfigFromFlags("", configPath)
+	if len(client.Credentials.Host) > 0 {
+		// No kubeconfig, API Server URL and credentials only
+		client.Restconfig = client.Credentials.restConfig()
+		if len(client.Namespace) == 0 {
+			client.Namespace = metav1.NamespaceDefault
+		}
+	} else {
+		loadingRules := newLoadingRules(client.KubeconfigPaths)
+		configOverrides := &clientcmd.ConfigOverrides{
+			CurrentContext: client.Context,
+		}
+		configOverrides.Context.Namespace = client.Namespace
+		client.Credentials.override(configOverrides)
+
+		// If there is no kubeconfig available and we are running
+		// inside a pod, the deferred loader uses the in-cluster config
+		client.Kubeconfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			loadingRules,
+			configOverrides)
+
+		client.Restconfig, err = client.Kubeconfig.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(client.Namespace) == 0 {
+			client.Namespace, _, err = client.Kubeconfig.Namespace()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(client.Impersonate.UserName) > 0 || len(client.Impersonate.Groups) > 0 {
+		client.Restconfig.Impersonate = client.Impersonate
+	}
 
 	// QPS and Burst settings helps users that want to increase
 	// the number of call per second against API Server, common
@@ -71,52 +195,83 @@ func (client *Client) Connect() (*Client, error) {
 	// QPS: 6000 Bust: 30000
 	// QPS: 1000 Bust: 1000 etc
 	if client.QPS > 0 {
-		config.QPS = client.QPS
+		client.Restconfig.QPS = client.QPS
 	}
 
 	if client.Burst > 0 {
-		config.Burst = client.Burst
+		client.Restconfig.Burst = client.Burst
+	}
+
+	if client.Journal != nil {
+		client.Restconfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &RecordingTransport{Base: rt, Journal: client.Journal}
+		})
 	}
 
+	// Requests and rate limiter waits are available via Stats()
+	client.instrument()
+
+	client.Restclientset, err = kubernetes.NewForConfig(client.rateLimited(client.Restconfig))
 	if err != nil {
 		return nil, err
 	}
 
-	client.Clientset, err = kubernetes.NewForConfig(config)
+	client.Dynamic, err = dynamic.NewForConfig(client.rateLimited(client.Restconfig))
 	if err != nil {
 		return nil, err
 	}
+	client.RESTMapper = newDiscoveryRESTMapper(client.Restclientset.Discovery())
+
+	if client.Executor == nil {
+		client.Executor = &SPDYExecutor{
+			Clientset:  client.Restclientset,
+			Restconfig: client.Restconfig,
+		}
+	}
+
+	if client.Journal != nil {
+		if _, ok := client.Executor.(*RecordingExecutor); !ok {
+			client.Executor = &RecordingExecutor{
+				Executor: client.Executor,
+				Journal:  client.Journal,
+			}
+		}
+		if client.HTTPClient == nil {
+			client.HTTPClient = &http.Client{
+				Transport: &RecordingTransport{
+					Base:    http.DefaultTransport,
+					Journal: client.Journal,
+				},
+			}
+		}
+	}
 
-	client.KubeClientFromConfig()
 	return client, nil
 }
 
-// KubeClientFromConfig will provide the REST interface
-// to cluster
-//
-// Args:
-//
-// Returns:
-//   - Client struct or error
-func (client *Client) KubeClientFromConfig() (*Client, error) {
-	var err error
-
+// newLoadingRules will provide the kubeconfig loading rules,
+// a single path must exist, multiple paths are merged in order
+// and without paths $KUBECONFIG or $HOME/.kube/config are used
+func newLoadingRules(paths []string) *clientcmd.ClientConfigLoadingRules {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-
-	client.Kubeconfig = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		loadingRules,
-		configOverrides)
-
-	client.Restconfig, err = client.Kubeconfig.ClientConfig()
-	if err != nil {
-		return nil, err
+	if len(paths) == 1 {
+		loadingRules.ExplicitPath = paths[0]
+	} else if len(paths) > 1 {
+		loadingRules.Precedence = paths
 	}
+	return loadingRules
+}
 
-	client.Restclientset, err = kubernetes.NewForConfig(client.Restconfig)
-	if err != nil {
-		return nil, err
+// Log will send the message to the client Logger
+//
+// Args:
+//	- logger.Level
+//	- message
+//	- pairs of key and value
+func (client *Client) Log(level logger.Level, msg string, keysAndValues ...interface{}) {
+	l := client.Logger
+	if l == nil {
+		l = logger.Default()
 	}
-
-	return client, nil
+	l.Log(level, msg, keysAndValues...)
 }
@@ -0,0 +1,368 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
+)
+
+// DefaultConfigFile is the config file loaded from the working
+// directory when K8DEVEL_CONFIG is not set
+const DefaultConfigFile = "k8devel.yaml"
+
+// Environment variables overriding the config file
+const (
+	EnvConfig           = "K8DEVEL_CONFIG"     // path of the config file, "off" disables it
+	EnvKubeconfig       = "K8DEVEL_KUBECONFIG" // list of paths like $KUBECONFIG
+	EnvContext          = "K8DEVEL_CONTEXT"
+	EnvNamespace        = "K8DEVEL_NAMESPACE"
+	EnvTimeoutTaskInSec = "K8DEVEL_TIMEOUT_TASK_IN_SEC"
+	EnvMaxAttempts      = "K8DEVEL_MAX_ATTEMPTS_PER_TASK"
+	EnvQPS              = "K8DEVEL_QPS"
+	EnvBurst            = "K8DEVEL_BURST"
+	EnvLogLevel         = "K8DEVEL_LOG_LEVEL"  // debug, info, warn, error or off
+	EnvLogOutput        = "K8DEVEL_LOG_OUTPUT" // stdout, stderr or a file path
+	EnvDryRun           = "K8DEVEL_DRY_RUN"    // none, server or client
+)
+
+// Config holds the client settings from a config file or the
+// environment, nil or empty fields are not set, example of
+// k8devel.yaml:
+//	kubeconfig:
+//	- /tmp/kubeconfig
+//	context: kind-kind
+//	timeoutTaskInSec: 120
+//	numberMaxOfAttemptsPerTask: 10
+//	qps: 100
+//	burst: 200
+//	logLevel: info
+//	dryRun: server
+type Config struct {
+	Kubeconfig                 []string `json:"kubeconfig,omitempty"`
+	Context                    string   `json:"context,omitempty"`
+	Namespace                  string   `json:"namespace,omitempty"`
+	TimeoutTaskInSec           *int     `json:"timeoutTaskInSec,omitempty"`
+	NumberMaxOfAttemptsPerTask *int     `json:"numberMaxOfAttemptsPerTask,omitempty"`
+	QPS                        *float32 `json:"qps,omitempty"`
+	Burst                      *int     `json:"burst,omitempty"`
+	LogLevel                   string   `json:"logLevel,omitempty"`  // debug, info, warn, error or off
+	LogOutput                  string   `json:"logOutput,omitempty"` // stdout, stderr (default) or a file path
+	DryRun                     string   `json:"dryRun,omitempty"`    // none, server or client
+}
+
+// ReadConfig will read a config file, unknown fields are errors
+//
+// Args:
+//	- path of the YAML (or JSON) file
+//
+// Returns:
+//	- pointer to Config or error
+func ReadConfig(path string) (*Config, error) {
+	op := "read config " + path
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, k8errors.Wrap(op, err)
+	}
+
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, k8errors.New(k8errors.ErrInvalidSpec, op, "%s", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, k8errors.Wrap(op, err)
+	}
+	return config, nil
+}
+
+// ConfigFromEnv will read the K8DEVEL_* environment variables
+//
+// Returns:
+//	- pointer to Config or error
+func ConfigFromEnv() (*Config, error) {
+	op := "read config from environment"
+	config := &Config{
+		Context:   os.Getenv(EnvContext),
+		Namespace: os.Getenv(EnvNamespace),
+		LogLevel:  os.Getenv(EnvLogLevel),
+		LogOutput: os.Getenv(EnvLogOutput),
+		DryRun:    os.Getenv(EnvDryRun),
+	}
+	if paths := os.Getenv(EnvKubeconfig); paths != "" {
+		config.Kubeconfig = filepath.SplitList(paths)
+	}
+
+	for env, field := range map[string]**int{
+		EnvTimeoutTaskInSec: &config.TimeoutTaskInSec,
+		EnvMaxAttempts:      &config.NumberMaxOfAttemptsPerTask,
+		EnvBurst:            &config.Burst,
+	} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, k8errors.New(k8errors.ErrInvalidSpec, op,
+				"%s: %q is not an integer", env, value)
+		}
+		*field = &i
+	}
+
+	if value := os.Getenv(EnvQPS); value != "" {
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return nil, k8errors.New(k8errors.ErrInvalidSpec, op,
+				"%s: %q is not a number", EnvQPS, value)
+		}
+		qps := float32(f)
+		config.QPS = &qps
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, k8errors.Wrap(op, err)
+	}
+	return config, nil
+}
+
+// Validate will check the values of the config
+//
+// Returns:
+//	- error (ErrInvalidSpec) or nil
+func (config *Config) Validate() error {
+	op := "validate config"
+	if config.TimeoutTaskInSec != nil && *config.TimeoutTaskInSec < 0 {
+		return k8errors.New(k8errors.ErrInvalidSpec, op,
+			"timeoutTaskInSec must be >= 0, got %d", *config.TimeoutTaskInSec)
+	}
+	if config.NumberMaxOfAttemptsPerTask != nil && *config.NumberMaxOfAttemptsPerTask < 0 {
+		return k8errors.New(k8errors.ErrInvalidSpec, op,
+			"numberMaxOfAttemptsPerTask must be >= 0, got %d", *config.NumberMaxOfAttemptsPerTask)
+	}
+	if config.QPS != nil && *config.QPS < 0 {
+		return k8errors.New(k8errors.ErrInvalidSpec, op,
+			"qps must be >= 0, got %v", *config.QPS)
+	}
+	if config.Burst != nil && *config.Burst < 0 {
+		return k8errors.New(k8errors.ErrInvalidSpec, op,
+			"burst must be >= 0, got %d", *config.Burst)
+	}
+	if config.LogLevel != "" && config.LogLevel != "off" {
+		if _, err := logger.ParseLevel(config.LogLevel); err != nil {
+			return k8errors.New(k8errors.ErrInvalidSpec, op,
+				"logLevel: %s, valid: debug, info, warn, error or off", err)
+		}
+	}
+	if _, err := parseDryRun(config.DryRun); err != nil {
+		return k8errors.New(k8errors.ErrInvalidSpec, op, "dryRun: %s", err)
+	}
+	return nil
+}
+
+// Merge will override the config with the fields set in other
+//
+// Args:
+//	- pointer to Config, nil is ignored
+func (config *Config) Merge(other *Config) {
+	if other == nil {
+		return
+	}
+	if len(other.Kubeconfig) > 0 {
+		config.Kubeconfig = other.Kubeconfig
+	}
+	if other.Context != "" {
+		config.Context = other.Context
+	}
+	if other.Namespace != "" {
+		config.Namespace = other.Namespace
+	}
+	if other.TimeoutTaskInSec != nil {
+		config.TimeoutTaskInSec = other.TimeoutTaskInSec
+	}
+	if other.NumberMaxOfAttemptsPerTask != nil {
+		config.NumberMaxOfAttemptsPerTask = other.NumberMaxOfAttemptsPerTask
+	}
+	if other.QPS != nil {
+		config.QPS = other.QPS
+	}
+	if other.Burst != nil {
+		config.Burst = other.Burst
+	}
+	if other.LogLevel != "" {
+		config.LogLevel = other.LogLevel
+	}
+	if other.LogOutput != "" {
+		config.LogOutput = other.LogOutput
+	}
+	if other.DryRun != "" {
+		config.DryRun = other.DryRun
+	}
+}
+
+// LoadConfig will load the config file (K8DEVEL_CONFIG or
+// k8devel.yaml from the working directory, when it exists)
+// and the K8DEVEL_* environment variables into the client.
+// Connect() calls it when it was not called before.
+//
+// Precedence, from lowest to highest:
+//	- fields set in code
+//	- config file
+//	- environment variables
+//
+// Returns:
+//	- error or nil
+func (client *Client) LoadConfig() error {
+	client.configLoaded = true
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return client.ApplyConfig(config)
+}
+
+// loadConfig will read the config file and the K8DEVEL_*
+// environment variables, see LoadConfig()
+func loadConfig() (*Config, error) {
+	config := &Config{}
+
+	path := os.Getenv(EnvConfig)
+	switch path {
+	case "off":
+	case "":
+		if _, err := os.Stat(DefaultConfigFile); err != nil {
+			break
+		}
+		path = DefaultConfigFile
+		fallthrough
+	default:
+		fileConfig, err := ReadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		config.Merge(fileConfig)
+	}
+
+	envConfig, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	config.Merge(envConfig)
+	return config, nil
+}
+
+// ApplyConfig will set the fields of the config into the client
+//
+// Args:
+//	- pointer to Config
+//
+// Returns:
+//	- error or nil
+func (client *Client) ApplyConfig(config *Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if len(config.Kubeconfig) > 0 {
+		client.KubeconfigPaths = config.Kubeconfig
+	}
+	if config.Context != "" {
+		client.Context = config.Context
+	}
+	if config.Namespace != "" {
+		client.Namespace = config.Namespace
+	}
+	if config.TimeoutTaskInSec != nil {
+		client.TimeoutTaskInSec = *config.TimeoutTaskInSec
+	}
+	if config.NumberMaxOfAttemptsPerTask != nil {
+		client.NumberMaxOfAttemptsPerTask = *config.NumberMaxOfAttemptsPerTask
+	}
+	if config.QPS != nil {
+		client.QPS = *config.QPS
+	}
+	if config.Burst != nil {
+		client.Burst = *config.Burst
+	}
+	if config.DryRun != "" {
+		client.DryRun, _ = parseDryRun(config.DryRun)
+	}
+
+	log, err := config.logger()
+	if err != nil {
+		return err
+	}
+	if log != nil {
+		client.Logger = log
+	}
+	return nil
+}
+
+// logger will provide the Logger of LogLevel and LogOutput, nil
+// when none of them is set
+func (config *Config) logger() (logger.Logger, error) {
+	if config.LogLevel == "off" {
+		return logger.Nop(), nil
+	}
+	if config.LogLevel == "" && config.LogOutput == "" {
+		return nil, nil
+	}
+	level := logger.LevelInfo
+	if config.LogLevel != "" {
+		level, _ = logger.ParseLevel(config.LogLevel)
+	}
+	w, err := logOutput(config.LogOutput)
+	if err != nil {
+		return nil, k8errors.Wrap("apply config", err)
+	}
+	return logger.NewWriter(w, level), nil
+}
+
+// parseDryRun will provide the DryRunMode from its name
+func parseDryRun(name string) (DryRunMode, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return DryRunNone, nil
+	case "server":
+		return DryRunServer, nil
+	case "client":
+		return DryRunClient, nil
+	}
+	return DryRunNone, k8errors.New(k8errors.ErrInvalidSpec, "parse dry run",
+		"unknown mode %q, valid: none, server or client", name)
+}
+
+// logOutput will provide the writer for the log messages, files
+// are opened for append and kept open
+func logOutput(output string) (io.Writer, error) {
+	switch output {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	}
+	return os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
@@ -0,0 +1,176 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/thekubeworld/k8devel/pkg/logger"
+)
+
+// DryRunMode controls if Create, Apply and Delete operations
+// mutate the cluster
+type DryRunMode string
+
+const (
+	// DryRunNone executes all operations
+	DryRunNone DryRunMode = ""
+	// DryRunServer sends all operations with DryRun: All, the
+	// API Server validates and admits them without persisting
+	DryRunServer DryRunMode = "server"
+	// DryRunClient doesn't send any operation to the API Server,
+	// the objects are only rendered and recorded
+	DryRunClient DryRunMode = "client"
+)
+
+// Operation describes a mutation in the cluster
+type Operation struct {
+	Verb      string         // create, delete, patch, apply
+	Kind      string         // i.e. Service, Deployment
+	Namespace string         // empty for cluster scoped objects
+	Name      string         // object name
+	Object    runtime.Object // object sent, nil for delete
+}
+
+// String will provide the operation description, example:
+// "create service default/foo"
+//
+// Returns:
+//	- string
+func (o Operation) String() string {
+	s := o.Verb + " " + strings.ToLower(o.Kind) + " "
+	if o.Namespace != "" {
+		s += o.Namespace + "/"
+	}
+	return s + o.Name
+}
+
+// IsDryRun will report if the client is in dry-run mode
+//
+// Returns:
+//	- bool
+func (client *Client) IsDryRun() bool {
+	return client.DryRun == DryRunServer || client.DryRun == DryRunClient
+}
+
+// CreateOptions will provide the metav1.CreateOptions based
+// on the client DryRun mode
+//
+// Returns:
+//	- metav1.CreateOptions
+func (client *Client) CreateOptions() metav1.CreateOptions {
+	return metav1.CreateOptions{DryRun: client.dryRunAll()}
+}
+
+// DeleteOptions will provide the metav1.DeleteOptions based
+// on the client DryRun mode
+//
+// Returns:
+//	- metav1.DeleteOptions
+func (client *Client) DeleteOptions() metav1.DeleteOptions {
+	return metav1.DeleteOptions{DryRun: client.dryRunAll()}
+}
+
+// PatchOptions will provide the metav1.PatchOptions based
+// on the client DryRun mode
+//
+// Returns:
+//	- metav1.PatchOptions
+func (client *Client) PatchOptions() metav1.PatchOptions {
+	return metav1.PatchOptions{DryRun: client.dryRunAll()}
+}
+
+// UpdateOptions will provide the metav1.UpdateOptions based
+// on the client DryRun mode
+//
+// Returns:
+//	- metav1.UpdateOptions
+func (client *Client) UpdateOptions() metav1.UpdateOptions {
+	return metav1.UpdateOptions{DryRun: client.dryRunAll()}
+}
+
+func (client *Client) dryRunAll() []string {
+	if client.DryRun == DryRunServer {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
+
+// Do will execute a mutation honoring the DryRun mode: with
+// DryRunClient fn is not called, with DryRunServer fn is
+// called (it must use CreateOptions(), DeleteOptions() or
+// PatchOptions()). In dry-run the operation is recorded and
+// available via DryRunOperations(). fn is retried based on
+// the RetryPolicy.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- Operation
+//	- operation function
+//
+// Returns:
+//	- nil, error from operation or TimeoutError
+func (client *Client) Do(ctx context.Context,
+	op Operation,
+	fn func(ctx context.Context) error) error {
+
+	if client.DryRun != DryRunClient {
+		if err := client.Retry(ctx, op.String(), fn); err != nil {
+			return err
+		}
+	}
+
+	if client.IsDryRun() {
+		client.dryRunMutex.Lock()
+		client.dryRunOperations = append(client.dryRunOperations, op)
+		client.dryRunMutex.Unlock()
+
+		client.Log(logger.LevelInfo, "Dry run",
+			"mode", string(client.DryRun),
+			"verb", op.Verb,
+			"kind", op.Kind,
+			"namespace", op.Namespace,
+			"name", op.Name)
+	}
+	return nil
+}
+
+// DryRunOperations will provide the operations recorded in
+// dry-run mode, in the order they were executed
+//
+// Returns:
+//	- slice of Operation
+func (client *Client) DryRunOperations() []Operation {
+	client.dryRunMutex.Lock()
+	defer client.dryRunMutex.Unlock()
+
+	ops := make([]Operation, len(client.dryRunOperations))
+	copy(ops, client.dryRunOperations)
+	return ops
+}
+
+// ResetDryRunOperations will clear the operations recorded
+// in dry-run mode
+func (client *Client) ResetDryRunOperations() {
+	client.dryRunMutex.Lock()
+	client.dryRunOperations = nil
+	client.dryRunMutex.Unlock()
+}
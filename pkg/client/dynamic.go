@@ -0,0 +1,174 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/restmapper"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// clusterScopedKinds are the kinds from client-go scheme that
+// are not namespaced, see ClusterScoped()
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"ComponentStatus":                true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CustomResourceDefinition":       true,
+	"FlowSchema":                     true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"PriorityLevelConfiguration":     true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+// ClusterScoped will report if a kind of client-go scheme is not
+// namespaced, used by the RESTMapper of NewFake() and when no
+// API Server is at hand. Custom resources are reported as
+// namespaced.
+//
+// Args:
+//	- kind, i.e. ClusterRole
+//
+// Returns:
+//	- true when not namespaced
+func ClusterScoped(kind string) bool {
+	return clusterScopedKinds[kind]
+}
+
+// newDiscoveryRESTMapper will provide a RESTMapper backed by the
+// discovery of the API Server, cached in memory
+func newDiscoveryRESTMapper(d discovery.DiscoveryInterface) meta.RESTMapper {
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(d))
+}
+
+// newSchemeRESTMapper will provide a RESTMapper with all kinds
+// from client-go scheme, no API Server required
+func newSchemeRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(scheme.Scheme.PrioritizedVersionsAllGroups())
+	for gvk := range scheme.Scheme.AllKnownTypes() {
+		if gvk.Version == "__internal" ||
+			strings.HasSuffix(gvk.Kind, "List") ||
+			strings.HasSuffix(gvk.Kind, "Options") {
+			continue
+		}
+		scope := meta.RESTScopeNamespace
+		if ClusterScoped(gvk.Kind) {
+			scope = meta.RESTScopeRoot
+		}
+		mapper.Add(gvk, scope)
+	}
+	return mapper
+}
+
+// RESTMapping will provide the resource of a kind from discovery,
+// when the kind is not found discovery is refreshed once (i.e.
+// a CustomResourceDefinition was just created)
+//
+// Args:
+//	- GroupVersionKind
+//
+// Returns:
+//	- pointer to meta.RESTMapping or error (ErrUnsupportedKind)
+func (client *Client) RESTMapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	op := "rest mapping " + gvk.String()
+	if client.RESTMapper == nil {
+		return nil, k8errors.New(k8errors.ErrNotConnected, op,
+			"Connect() must be called before")
+	}
+
+	mapping, err := client.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		if resettable, ok := client.RESTMapper.(interface{ Reset() }); ok {
+			resettable.Reset()
+			mapping, err = client.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if meta.IsNoMatchError(err) {
+		return nil, k8errors.New(k8errors.ErrUnsupportedKind, op,
+			"kind %s is not served by the cluster", gvk.Kind)
+	}
+	if err != nil {
+		return nil, k8errors.Wrap(op, err)
+	}
+	return mapping, nil
+}
+
+// ResourceFor will provide the dynamic client for the object
+// kind, namespaced objects without namespace are set to the
+// client Namespace and cluster scoped objects have the
+// namespace cleared
+//
+// Args:
+//	- pointer to unstructured.Unstructured
+//
+// Returns:
+//	- dynamic.ResourceInterface, pointer to meta.RESTMapping or error
+func (client *Client) ResourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, *meta.RESTMapping, error) {
+	gvk := obj.GroupVersionKind()
+	if len(gvk.Kind) == 0 || len(gvk.Version) == 0 {
+		return nil, nil, k8errors.New(k8errors.ErrInvalidSpec,
+			"resource for "+obj.GetName(),
+			"apiVersion and kind are required")
+	}
+
+	mapping, err := client.RESTMapping(gvk)
+	if err != nil {
+		return nil, nil, err
+	}
+	if client.Dynamic == nil {
+		return nil, nil, k8errors.New(k8errors.ErrNotConnected,
+			"resource for "+gvk.Kind,
+			"Connect() must be called before")
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		obj.SetNamespace("")
+		return client.Dynamic.Resource(mapping.Resource), mapping, nil
+	}
+
+	if len(obj.GetNamespace()) == 0 {
+		namespace := client.Namespace
+		if len(namespace) == 0 {
+			namespace = metav1.NamespaceDefault
+		}
+		obj.SetNamespace(namespace)
+	}
+	return client.Dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace()), mapping, nil
+}
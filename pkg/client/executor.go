@@ -0,0 +1,263 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	kubernetes "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	utilexec "k8s.io/client-go/util/exec"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// Executor executes commands inside a pod, pod.ExecCmd and
+// all modules built on top of it (firewall, curl, apt, dnf,
+// dpkg, rpm) go through the Executor from the Client struct
+type Executor interface {
+	Exec(ctx context.Context,
+		podName string,
+		namespace string,
+		cmd []string) (bytes.Buffer, bytes.Buffer, error)
+}
+
+// SPDYExecutor executes commands via the pods/exec
+// subresource from the API Server, it's the default
+// Executor set by Connect()
+type SPDYExecutor struct {
+	Clientset  kubernetes.Interface
+	Restconfig *rest.Config
+}
+
+// Exec executes a command inside a POD
+//
+// Args:
+//	- context for cancellation and deadline
+//	- pod name
+//	- namespace
+//	- command as array (string)
+//
+// Returns:
+//	stdout, stderr as bytes.Buffer or error
+func (e *SPDYExecutor) Exec(ctx context.Context,
+	podName string,
+	namespace string,
+	cmd []string) (bytes.Buffer, bytes.Buffer, error) {
+
+	var stdout, stderr bytes.Buffer
+	if e.Restconfig == nil {
+		return stdout, stderr, k8errors.New(k8errors.ErrNotConnected,
+			"exec",
+			"exec requires a client connected to a cluster")
+	}
+
+	restClient := e.Clientset.CoreV1().RESTClient()
+
+	req := restClient.Post().Resource("pods").Name(podName).
+		Namespace(namespace).SubResource("exec")
+	option := &v1.PodExecOptions{
+		Command: cmd,
+		Stdin:   false,
+		Stdout:  true,
+		Stderr:  true,
+		TTY:     true,
+	}
+
+	req.VersionedParams(
+		option,
+		scheme.ParameterCodec,
+	)
+	transport, upgrader, err := spdy.RoundTripperFor(e.Restconfig)
+	if err != nil {
+		return stdout, stderr, err
+	}
+
+	// The upgrader closes the stream connection when ctx is done,
+	// so a hung command doesn't block the caller forever
+	exec, err := remotecommand.NewSPDYExecutorForTransports(
+		transport,
+		&cancelableUpgrader{Upgrader: upgrader, ctx: ctx},
+		"POST",
+		req.URL())
+	if err != nil {
+		return stdout, stderr, err
+	}
+	err = exec.Stream(remotecommand.StreamOptions{
+		Stdin:  nil,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if ctx.Err() != nil {
+		return stdout, stderr, ctx.Err()
+	}
+	if err != nil {
+		return stdout, stderr, err
+	}
+	return stdout, stderr, nil
+}
+
+// cancelableUpgrader closes the upgraded exec connection
+// as soon as the context is done
+type cancelableUpgrader struct {
+	spdy.Upgrader
+	ctx context.Context
+}
+
+// NewConnection will upgrade the connection and watch the context
+//
+// Args:
+//	- http response from the exec request
+//
+// Returns:
+//	httpstream.Connection or error
+func (u *cancelableUpgrader) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := u.Upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-u.ctx.Done():
+			conn.Close()
+		case <-conn.CloseChan():
+		}
+	}()
+	return conn, nil
+}
+
+// FakeResult is the scripted result of a command
+// executed by the FakeExecutor
+type FakeResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int   // non zero returns the same error type as a real exec
+	Err      error // optional, i.e. to simulate a pod not found
+}
+
+// FakeExecCall records a command executed by the FakeExecutor
+type FakeExecCall struct {
+	PodName   string
+	Namespace string
+	Command   []string
+}
+
+// FakeExecutor is an in-memory Executor, it doesn't talk
+// with any cluster and returns the results scripted by
+// command. Commands without a result behave like a shell
+// when the command is not found (exit code 127).
+//
+//	fe := client.NewFakeExecutor()
+//	fe.AddResult([]string{"iptables-save"}, client.FakeResult{
+//		Stdout: "*filter\n",
+//	})
+//	c.Executor = fe
+type FakeExecutor struct {
+	Results map[string]FakeResult // key is the command joined by spaces
+	Calls   []FakeExecCall
+
+	mutex sync.Mutex
+}
+
+// NewFakeExecutor will provide an empty FakeExecutor
+//
+// Returns:
+//	- FakeExecutor struct
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{Results: map[string]FakeResult{}}
+}
+
+// AddResult will script the result for a command
+//
+// Args:
+//	- command as array (string)
+//	- FakeResult struct
+func (f *FakeExecutor) AddResult(cmd []string, result FakeResult) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.Results == nil {
+		f.Results = map[string]FakeResult{}
+	}
+	f.Results[strings.Join(cmd, " ")] = result
+}
+
+// Exec will record the call and return the scripted result
+//
+// Args:
+//	- context for cancellation and deadline
+//	- pod name
+//	- namespace
+//	- command as array (string)
+//
+// Returns:
+//	stdout, stderr as bytes.Buffer or error
+func (f *FakeExecutor) Exec(ctx context.Context,
+	podName string,
+	namespace string,
+	cmd []string) (bytes.Buffer, bytes.Buffer, error) {
+
+	var stdout, stderr bytes.Buffer
+	if err := ctx.Err(); err != nil {
+		return stdout, stderr, err
+	}
+	if len(cmd) == 0 {
+		return stdout, stderr, k8errors.New(k8errors.ErrInvalidSpec,
+			"exec",
+			"empty command")
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.Calls = append(f.Calls, FakeExecCall{
+		PodName:   podName,
+		Namespace: namespace,
+		Command:   cmd,
+	})
+
+	result, found := f.Results[strings.Join(cmd, " ")]
+	if !found {
+		result = FakeResult{
+			Stderr:   fmt.Sprintf("%s: command not found\n", cmd[0]),
+			ExitCode: 127,
+		}
+	}
+
+	stdout.WriteString(result.Stdout)
+	stderr.WriteString(result.Stderr)
+	if result.Err != nil {
+		return stdout, stderr, result.Err
+	}
+	if result.ExitCode != 0 {
+		return stdout, stderr, utilexec.CodeExitError{
+			Err:  fmt.Errorf("command terminated with exit code %d", result.ExitCode),
+			Code: result.ExitCode,
+		}
+	}
+	return stdout, stderr, nil
+}
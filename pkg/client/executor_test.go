@@ -0,0 +1,124 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	utilexec "k8s.io/client-go/util/exec"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+func TestFakeExecutor(t *testing.T) {
+	podErr := errors.New("pod not found")
+
+	fe := NewFakeExecutor()
+	fe.AddResult([]string{"iptables-save"}, FakeResult{Stdout: "*filter\n"})
+	fe.AddResult([]string{"ipvsadm", "-Ln"}, FakeResult{Stderr: "no ipvs\n", ExitCode: 2})
+	fe.AddResult([]string{"cat", "/etc/hosts"}, FakeResult{Err: podErr})
+
+	tests := []struct {
+		name       string
+		cmd        []string
+		wantStdout string
+		wantStderr string
+		wantCode   int // exit code of utilexec.ExitError, 0 when not one
+		wantErr    error
+	}{
+		{
+			name:       "scripted",
+			cmd:        []string{"iptables-save"},
+			wantStdout: "*filter\n",
+		},
+		{
+			name:       "exit code",
+			cmd:        []string{"ipvsadm", "-Ln"},
+			wantStderr: "no ipvs\n",
+			wantCode:   2,
+		},
+		{
+			name:    "error",
+			cmd:     []string{"cat", "/etc/hosts"},
+			wantErr: podErr,
+		},
+		{
+			name:       "not scripted",
+			cmd:        []string{"conntrack", "-L"},
+			wantStderr: "conntrack: command not found\n",
+			wantCode:   127,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, err := fe.Exec(context.Background(), "kube-proxy", "kube-system", tt.cmd)
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", got, tt.wantStdout)
+			}
+			if got := stderr.String(); got != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", got, tt.wantStderr)
+			}
+
+			var exitErr utilexec.ExitError
+			switch {
+			case tt.wantCode != 0:
+				if !errors.As(err, &exitErr) || exitErr.ExitStatus() != tt.wantCode {
+					t.Errorf("error = %v, want exit code %d", err, tt.wantCode)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	_, _, err := fe.Exec(context.Background(), "kube-proxy", "kube-system", nil)
+	if !errors.Is(err, k8errors.ErrInvalidSpec) {
+		t.Errorf("empty command error = %v, want %v", err, k8errors.ErrInvalidSpec)
+	}
+
+	// the empty command is not recorded
+	want := []FakeExecCall{
+		{PodName: "kube-proxy", Namespace: "kube-system", Command: []string{"iptables-save"}},
+		{PodName: "kube-proxy", Namespace: "kube-system", Command: []string{"ipvsadm", "-Ln"}},
+		{PodName: "kube-proxy", Namespace: "kube-system", Command: []string{"cat", "/etc/hosts"}},
+		{PodName: "kube-proxy", Namespace: "kube-system", Command: []string{"conntrack", "-L"}},
+	}
+	if !reflect.DeepEqual(fe.Calls, want) {
+		t.Errorf("Calls = %+v, want %+v", fe.Calls, want)
+	}
+}
+
+func TestFakeExecutorCanceled(t *testing.T) {
+	fe := NewFakeExecutor()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := fe.Exec(ctx, "web", "default", []string{"true"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if len(fe.Calls) != 0 {
+		t.Errorf("Calls = %+v, want none", fe.Calls)
+	}
+}
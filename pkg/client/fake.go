@@ -0,0 +1,59 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// NewFake will provide a Client backed by the client-go fake
+// clientset, no cluster is required. It's useful for unit tests
+// of code using k8devel packages.
+//
+// The objects provided are preloaded in the fake clientset, i.e:
+//
+//	c := client.NewFake(&v1.Namespace{
+//		ObjectMeta: metav1.ObjectMeta{Name: "foobar"},
+//	})
+//	err := namespace.Delete(ctx, c, "foobar")
+//
+// Commands from pod.ExecCmd are served by a FakeExecutor. The
+// objects are also preloaded in a fake dynamic client, used by
+// apply and delete, it's not shared with the fake clientset.
+//
+// Args:
+//	- objects to preload
+//
+// Returns:
+//	- Client struct
+func NewFake(objects ...runtime.Object) *Client {
+	clientset := fake.NewSimpleClientset(objects...)
+
+	return &Client{
+		Clientset:                  clientset,
+		Restclientset:              clientset,
+		Dynamic:                    dynamicfake.NewSimpleDynamicClient(scheme.Scheme, objects...),
+		RESTMapper:                 newSchemeRESTMapper(),
+		Namespace:                  "default",
+		TimeoutTaskInSec:           1,
+		NumberMaxOfAttemptsPerTask: 1,
+		Executor:                   NewFakeExecutor(),
+	}
+}
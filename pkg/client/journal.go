@@ -0,0 +1,400 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// JournalEntry is one line of the journal, an API request with
+// the response, a download or a command executed in a pod
+type JournalEntry struct {
+	Time         time.Time `json:"time"`
+	Verb         string    `json:"verb"`             // get, list, watch, create, update, patch, delete, exec, download
+	Method       string    `json:"method,omitempty"` // HTTP method
+	URL          string    `json:"url,omitempty"`
+	Resource     string    `json:"resource,omitempty"`
+	Subresource  string    `json:"subresource,omitempty"`
+	Namespace    string    `json:"namespace,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	Status       int       `json:"status,omitempty"` // HTTP status code
+	LatencyMs    float64   `json:"latencyMs"`
+	ContentType  string    `json:"contentType,omitempty"`
+	RequestBody  string    `json:"requestBody,omitempty"`
+	ResponseBody string    `json:"responseBody,omitempty"`
+	Error        string    `json:"error,omitempty"`
+
+	// exec only
+	Command  []string `json:"command,omitempty"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+	ExitCode int      `json:"exitCode,omitempty"`
+}
+
+// Journal writes JournalEntry as JSON lines
+type Journal struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// NewJournal will create a Journal writing into w
+//
+// Args:
+//	- io.Writer
+//
+// Returns:
+//	- pointer to Journal
+func NewJournal(w io.Writer) *Journal {
+	j := &Journal{encoder: json.NewEncoder(w)}
+	if c, ok := w.(io.Closer); ok {
+		j.closer = c
+	}
+	return j
+}
+
+// OpenJournal will create a Journal appending into a file
+//
+// Args:
+//	- path of the file
+//
+// Returns:
+//	- pointer to Journal or error
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJournal(f), nil
+}
+
+// Record will write the entry as a line in the journal
+//
+// Args:
+//	- JournalEntry
+//
+// Returns:
+//	- error or nil
+func (j *Journal) Record(entry JournalEntry) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.encoder.Encode(entry)
+}
+
+// Close will close the underlying writer if it's an io.Closer
+//
+// Returns:
+//	- error or nil
+func (j *Journal) Close() error {
+	if j.closer == nil {
+		return nil
+	}
+	return j.closer.Close()
+}
+
+// ReadJournal will read all entries from a journal file
+//
+// Args:
+//	- path of the file
+//
+// Returns:
+//	- slice of JournalEntry or error
+func ReadJournal(path string) ([]JournalEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []JournalEntry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var entry JournalEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Redacted replaces the credentials in the bodies recorded: the
+// values of Secrets and the token of TokenRequests
+const Redacted = "REDACTED" // valid base64, replayed Secrets can be decoded
+
+// RecordingTransport is a http.RoundTripper writing every request
+// and response into the Journal, credentials are replaced by
+// Redacted so the journal can be shared
+type RecordingTransport struct {
+	Base    http.RoundTripper
+	Journal *Journal
+}
+
+// RoundTrip will execute the request via Base and record it
+//
+// Args:
+//	- http.Request
+//
+// Returns:
+//	- http.Response or error
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := newJournalEntry(req)
+
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := ioutil.ReadAll(body)
+			body.Close()
+			entry.RequestBody = redact(entry, data)
+		}
+	} else if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+		entry.RequestBody = redact(entry, data)
+	}
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	entry.LatencyMs = float64(time.Since(start)) / float64(time.Millisecond)
+	if err != nil {
+		entry.Error = err.Error()
+		t.Journal.Record(entry)
+		return resp, err
+	}
+
+	entry.Status = resp.StatusCode
+	entry.ContentType = resp.Header.Get("Content-Type")
+
+	// Streams (watch, logs follow) are not buffered
+	if entry.Verb != "watch" {
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entry.ResponseBody = redact(entry, data)
+	}
+
+	t.Journal.Record(entry)
+	return resp, nil
+}
+
+// redact will replace the values of Secrets (data and
+// stringData) and the token of TokenRequests in the body. Bodies
+// of secrets or tokens that aren't JSON are replaced as a whole,
+// other bodies are kept as sent.
+func redact(entry JournalEntry, data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sensitive := entry.Resource == "secrets" || entry.Subresource == "token"
+
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		if sensitive {
+			return Redacted
+		}
+		return string(data)
+	}
+
+	// patches of secrets might not have the kind
+	secret := entry.Resource == "secrets" && entry.Subresource == ""
+	if !redactValue(body, secret) {
+		return string(data)
+	}
+	redacted, err := json.Marshal(body)
+	if err != nil {
+		return Redacted
+	}
+	return string(redacted)
+}
+
+// redactValue will replace the credentials of the object and the
+// items of lists, secret forces the object (or the items, they
+// don't have the kind) to be read as Secret
+//
+// Returns:
+//	- true when something was replaced
+func redactValue(value interface{}, secret bool) bool {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	redacted := false
+	switch {
+	case secret || obj["kind"] == "Secret":
+		for _, field := range []string{"data", "stringData"} {
+			values, ok := obj[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for key := range values {
+				values[key] = Redacted
+				redacted = true
+			}
+		}
+	case obj["kind"] == "TokenRequest":
+		if status, ok := obj["status"].(map[string]interface{}); ok {
+			if _, ok := status["token"]; ok {
+				status["token"] = Redacted
+				redacted = true
+			}
+		}
+	}
+
+	if items, ok := obj["items"].([]interface{}); ok {
+		for _, item := range items {
+			if redactValue(item, secret) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// newJournalEntry will fill the entry from the request URL, paths
+// from the API Server are:
+//	/api/v1/namespaces/{namespace}/{resource}/{name}/{subresource}
+//	/apis/{group}/{version}/{resource}/{name}
+func newJournalEntry(req *http.Request) JournalEntry {
+	entry := JournalEntry{
+		Time:   time.Now().UTC(),
+		Method: req.Method,
+		URL:    req.URL.String(),
+	}
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		parts = parts[3:]
+	case parts[0] == "api" || parts[0] == "apis" || parts[0] == "version":
+		// discovery
+		entry.Verb = "get"
+		return entry
+	default:
+		entry.Verb = "download"
+		return entry
+	}
+
+	// namespaced objects, but not namespaces/{name}/status or finalize
+	if len(parts) >= 3 && parts[0] == "namespaces" &&
+		parts[2] != "status" && parts[2] != "finalize" {
+		entry.Namespace = parts[1]
+		parts = parts[2:]
+	}
+	if len(parts) > 0 {
+		entry.Resource = parts[0]
+	}
+	if len(parts) > 1 {
+		entry.Name = parts[1]
+	}
+	if len(parts) > 2 {
+		entry.Subresource = parts[2]
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		entry.Verb = "get"
+		if entry.Name == "" {
+			entry.Verb = "list"
+		}
+		if req.URL.Query().Get("watch") == "true" {
+			entry.Verb = "watch"
+		}
+	case http.MethodPost:
+		entry.Verb = "create"
+	case http.MethodPut:
+		entry.Verb = "update"
+	case http.MethodPatch:
+		entry.Verb = "patch"
+	case http.MethodDelete:
+		entry.Verb = "delete"
+		if entry.Name == "" {
+			entry.Verb = "deletecollection"
+		}
+	default:
+		entry.Verb = strings.ToLower(req.Method)
+	}
+	return entry
+}
+
+// RecordingExecutor is an Executor writing every command and
+// output into the Journal
+type RecordingExecutor struct {
+	Executor Executor
+	Journal  *Journal
+}
+
+// Exec will execute the command via Executor and record it
+//
+// Args:
+//	- context for cancellation and deadline
+//	- pod name
+//	- namespace
+//	- command
+//
+// Returns:
+//	- stdout, stderr as bytes.Buffer or error
+func (e *RecordingExecutor) Exec(ctx context.Context,
+	podName string,
+	namespace string,
+	cmd []string) (bytes.Buffer, bytes.Buffer, error) {
+
+	start := time.Now()
+	stdout, stderr, err := e.Executor.Exec(ctx, podName, namespace, cmd)
+
+	entry := JournalEntry{
+		Time:        start.UTC(),
+		Verb:        "exec",
+		Resource:    "pods",
+		Subresource: "exec",
+		Namespace:   namespace,
+		Name:        podName,
+		LatencyMs:   float64(time.Since(start)) / float64(time.Millisecond),
+		Command:     cmd,
+		Stdout:      stdout.String(),
+		Stderr:      stderr.String(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		var exitErr utilexec.ExitError
+		if errors.As(err, &exitErr) {
+			entry.ExitCode = exitErr.ExitStatus()
+		}
+	}
+	e.Journal.Record(entry)
+
+	return stdout, stderr, err
+}
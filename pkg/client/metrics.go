@@ -0,0 +1,421 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// LatencyBuckets are the upper bounds (in seconds) of the
+// request latency histogram
+var LatencyBuckets = []float64{
+	0.005, 0.025, 0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60,
+}
+
+// Histogram of latencies, Counts[i] is the number of requests
+// with latency <= Buckets[i] (not cumulative), requests above
+// the last bucket are only in Count and Sum
+type Histogram struct {
+	Buckets []float64
+	Counts  []int64
+	Count   int64
+	Sum     time.Duration
+}
+
+func newHistogram() Histogram {
+	return Histogram{
+		Buckets: LatencyBuckets,
+		Counts:  make([]int64, len(LatencyBuckets)),
+	}
+}
+
+func (h *Histogram) observe(latency time.Duration) {
+	h.Count++
+	h.Sum += latency
+	seconds := latency.Seconds()
+	for i, bucket := range h.Buckets {
+		if seconds <= bucket {
+			h.Counts[i]++
+			return
+		}
+	}
+}
+
+// Mean will provide the mean latency
+//
+// Returns:
+//	- time.Duration
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// RequestStats are the stats of the requests with the same
+// verb and resource
+type RequestStats struct {
+	Verb     string
+	Resource string
+	Count    int64
+	Errors   int64         // transport errors and status code >= 400
+	Codes    map[int]int64 // requests per status code, 0 for transport errors
+	Latency  Histogram     // time until the response headers
+}
+
+// Stats is a snapshot of the API requests made by the client
+type Stats struct {
+	Since            time.Time      // Start of the collection
+	Requests         []RequestStats // sorted by Resource and Verb
+	RateLimiterWaits int64          // requests that went through the rate limiter
+	RateLimiterWait  time.Duration  // total time waiting in the rate limiter
+}
+
+// Total will provide the sum of requests and errors
+//
+// Returns:
+//	- requests and errors
+func (s Stats) Total() (int64, int64) {
+	var requests, errors int64
+	for _, r := range s.Requests {
+		requests += r.Count
+		errors += r.Errors
+	}
+	return requests, errors
+}
+
+type requestKey struct {
+	verb     string
+	resource string
+}
+
+// metrics collects the stats of a client
+type metrics struct {
+	mutex            sync.Mutex
+	since            time.Time
+	requests         map[requestKey]*RequestStats
+	rateLimiterWaits int64
+	rateLimiterWait  time.Duration
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		since:    time.Now(),
+		requests: map[requestKey]*RequestStats{},
+	}
+}
+
+func (m *metrics) observeRequest(verb string, resource string, code int, latency time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := requestKey{verb: verb, resource: resource}
+	stats, ok := m.requests[key]
+	if !ok {
+		stats = &RequestStats{
+			Verb:     verb,
+			Resource: resource,
+			Codes:    map[int]int64{},
+			Latency:  newHistogram(),
+		}
+		m.requests[key] = stats
+	}
+	stats.Count++
+	stats.Codes[code]++
+	if code == 0 || code >= http.StatusBadRequest {
+		stats.Errors++
+	}
+	stats.Latency.observe(latency)
+}
+
+func (m *metrics) observeRateLimiter(wait time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.rateLimiterWaits++
+	m.rateLimiterWait += wait
+}
+
+func (m *metrics) snapshot() Stats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats := Stats{
+		Since:            m.since,
+		RateLimiterWaits: m.rateLimiterWaits,
+		RateLimiterWait:  m.rateLimiterWait,
+	}
+	for _, r := range m.requests {
+		copied := *r
+		copied.Codes = map[int]int64{}
+		for code, count := range r.Codes {
+			copied.Codes[code] = count
+		}
+		copied.Latency.Counts = append([]int64(nil), r.Latency.Counts...)
+		stats.Requests = append(stats.Requests, copied)
+	}
+	sort.Slice(stats.Requests, func(i, j int) bool {
+		if stats.Requests[i].Resource != stats.Requests[j].Resource {
+			return stats.Requests[i].Resource < stats.Requests[j].Resource
+		}
+		return stats.Requests[i].Verb < stats.Requests[j].Verb
+	})
+	return stats
+}
+
+// MetricsTransport is a http.RoundTripper collecting the stats
+// of every request
+type MetricsTransport struct {
+	Base    http.RoundTripper
+	metrics *metrics
+}
+
+// RoundTrip will execute the request via Base and collect stats
+//
+// Args:
+//	- http.Request
+//
+// Returns:
+//	- http.Response or error
+func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := newJournalEntry(req)
+
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	code := 0
+	if err == nil {
+		code = resp.StatusCode
+	}
+	t.metrics.observeRequest(entry.Verb, entry.Resource, code, time.Since(start))
+	return resp, err
+}
+
+// metricsRateLimiter wraps a flowcontrol.RateLimiter measuring
+// the time waiting for a token
+type metricsRateLimiter struct {
+	flowcontrol.RateLimiter
+	metrics *metrics
+}
+
+func (r *metricsRateLimiter) Accept() {
+	start := time.Now()
+	r.RateLimiter.Accept()
+	r.metrics.observeRateLimiter(time.Since(start))
+}
+
+func (r *metricsRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := r.RateLimiter.Wait(ctx)
+	r.metrics.observeRateLimiter(time.Since(start))
+	return err
+}
+
+// Stats will provide a snapshot of the API requests made since
+// Connect() or ResetStats()
+//
+// Returns:
+//	- Stats struct
+func (client *Client) Stats() Stats {
+	if client.metrics == nil {
+		return Stats{}
+	}
+	return client.metrics.snapshot()
+}
+
+// ResetStats will clear the stats collected
+func (client *Client) ResetStats() {
+	if client.metrics == nil {
+		return
+	}
+	m := client.metrics
+	m.mutex.Lock()
+	m.since = time.Now()
+	m.requests = map[requestKey]*RequestStats{}
+	m.rateLimiterWaits = 0
+	m.rateLimiterWait = 0
+	m.mutex.Unlock()
+}
+
+// Descriptions of the metrics provided by Collector()
+var (
+	requestsDesc = prometheus.NewDesc("k8devel_client_requests_total",
+		"Number of API requests by verb, resource and code.",
+		[]string{"verb", "resource", "code"}, nil)
+	requestErrorsDesc = prometheus.NewDesc("k8devel_client_request_errors_total",
+		"Number of API requests failed or with status code >= 400.",
+		[]string{"verb", "resource"}, nil)
+	requestDurationDesc = prometheus.NewDesc("k8devel_client_request_duration_seconds",
+		"Latency of API requests.",
+		[]string{"verb", "resource"}, nil)
+	rateLimiterWaitDesc = prometheus.NewDesc("k8devel_client_rate_limiter_wait_seconds",
+		"Time waiting in the client-side rate limiter.",
+		nil, nil)
+)
+
+// collector is the prometheus.Collector of the stats of a client
+type collector struct {
+	client *Client
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- requestsDesc
+	ch <- requestErrorsDesc
+	ch <- requestDurationDesc
+	ch <- rateLimiterWaitDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.Stats()
+	for _, r := range stats.Requests {
+		for code, count := range r.Codes {
+			ch <- prometheus.MustNewConstMetric(requestsDesc, prometheus.CounterValue,
+				float64(count), r.Verb, r.Resource, strconv.Itoa(code))
+		}
+		ch <- prometheus.MustNewConstMetric(requestErrorsDesc, prometheus.CounterValue,
+			float64(r.Errors), r.Verb, r.Resource)
+
+		// Histogram counts aren't cumulative, Prometheus buckets are
+		buckets := make(map[float64]uint64, len(r.Latency.Buckets))
+		var cumulative uint64
+		for i, bucket := range r.Latency.Buckets {
+			cumulative += uint64(r.Latency.Counts[i])
+			buckets[bucket] = cumulative
+		}
+		ch <- prometheus.MustNewConstHistogram(requestDurationDesc,
+			uint64(r.Latency.Count), r.Latency.Sum.Seconds(), buckets,
+			r.Verb, r.Resource)
+	}
+	ch <- prometheus.MustNewConstSummary(rateLimiterWaitDesc,
+		uint64(stats.RateLimiterWaits), stats.RateLimiterWait.Seconds(), nil)
+}
+
+// Collector will provide a prometheus.Collector of the stats, to
+// be registered with the metrics of the application, example:
+//	prometheus.MustRegister(c.Collector())
+// The metrics of several clients in the same registry must be
+// told apart by a label, i.e. for a client per cluster:
+//	prometheus.WrapRegistererWith(prometheus.Labels{"cluster": name},
+//		prometheus.DefaultRegisterer).MustRegister(c.Collector())
+//
+// Returns:
+//	- prometheus.Collector
+func (client *Client) Collector() prometheus.Collector {
+	return &collector{client: client}
+}
+
+// registry will provide a prometheus.Registry with only the
+// Collector() of the client
+func (client *Client) registry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(client.Collector())
+	return registry
+}
+
+// WritePrometheus will write the metrics of Collector() in
+// Prometheus text exposition format
+//
+// Args:
+//	- io.Writer
+//
+// Returns:
+//	- error or nil
+func (client *Client) WritePrometheus(w io.Writer) error {
+	families, err := client.registry().Gather()
+	if err != nil {
+		return err
+	}
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(w, family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MetricsHandler will provide a http.Handler serving only the
+// metrics of Collector(), example:
+//	http.Handle("/metrics", c.MetricsHandler())
+// Register Collector() instead to serve them with the metrics of
+// the application.
+//
+// Returns:
+//	- http.Handler
+func (client *Client) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(client.registry(), promhttp.HandlerOpts{})
+}
+
+// instrument will collect the stats of the requests made with
+// the restconfig, see rateLimited() for the rate limiter
+func (client *Client) instrument() {
+	if client.metrics == nil {
+		client.metrics = newMetrics()
+	}
+	m := client.metrics
+
+	client.Restconfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &MetricsTransport{Base: rt, metrics: m}
+	})
+}
+
+// rateLimited will provide a copy of the restconfig for a new
+// clientset with its own rate limiter, as client-go does, and
+// the time waiting in it collected. A RateLimiter set in the
+// restconfig is shared, as requested.
+//
+// Args:
+//	- rest.Config
+//
+// Returns:
+//	- copy of rest.Config
+func (client *Client) rateLimited(restconfig *rest.Config) *rest.Config {
+	config := rest.CopyConfig(restconfig)
+	if client.metrics == nil {
+		return config
+	}
+
+	limiter := config.RateLimiter
+	if limiter == nil {
+		qps, burst := config.QPS, config.Burst
+		if qps == 0 {
+			qps = rest.DefaultQPS
+		}
+		if burst == 0 {
+			burst = rest.DefaultBurst
+		}
+		if qps < 0 {
+			// rate limiter disabled
+			return config
+		}
+		limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	}
+	if _, ok := limiter.(*metricsRateLimiter); !ok {
+		config.RateLimiter = &metricsRateLimiter{RateLimiter: limiter, metrics: client.metrics}
+	}
+	return config
+}
@@ -0,0 +1,350 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// Registry keeps one Client per cluster, the cluster name is
+// the kubeconfig context, example:
+//	r := client.Registry{Contexts: []string{"kind-iptables", "kind-ipvs"}}
+//	r.Connect()
+//	results := r.FanOut(ctx, func(ctx context.Context, cluster string, c *client.Client) (interface{}, error) {
+//		return kubeproxy.DetectKubeProxyMode(ctx, c, "kube-proxy", "kube-proxy", "kube-system")
+//	})
+type Registry struct {
+	// KubeconfigPaths are the kubeconfig files to load, same
+	// rules as Client.KubeconfigPaths
+	KubeconfigPaths []string
+
+	// Contexts are the kubeconfig contexts to connect, when
+	// empty all contexts from kubeconfig are used
+	Contexts []string
+
+	// Setup is called for every Client before Connect(), i.e.
+	// to set Logger, RetryPolicy, QPS or TimeoutTaskInSec
+	Setup func(cluster string, c *Client)
+
+	// MaxConcurrency limits the clusters running a FanOut
+	// operation at the same time, 0 means no limit
+	MaxConcurrency int
+
+	mutex    sync.RWMutex
+	clusters []string
+	clients  map[string]*Client
+}
+
+// Connect will create and connect a Client for every context.
+// The config (see Client.LoadConfig) is loaded once and shared by
+// all clients, the Logger as well.
+//
+// Returns:
+//	- error or nil
+func (r *Registry) Connect() error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	// one Logger for all clusters, K8DEVEL_LOG_OUTPUT is opened once
+	log, err := config.logger()
+	if err != nil {
+		return err
+	}
+	clientConfig := *config
+	clientConfig.LogLevel = ""
+	clientConfig.LogOutput = ""
+
+	// kubeconfig might come from k8devel.yaml or K8DEVEL_KUBECONFIG
+	kubeconfigPaths := r.KubeconfigPaths
+	if len(config.Kubeconfig) > 0 {
+		kubeconfigPaths = config.Kubeconfig
+	}
+
+	contexts := r.Contexts
+	if len(contexts) == 0 {
+		kubeconfig, err := newLoadingRules(kubeconfigPaths).Load()
+		if err != nil {
+			return err
+		}
+		for name := range kubeconfig.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+		if len(contexts) == 0 {
+			return k8errors.New(k8errors.ErrNotFound,
+				"registry connect",
+				"no contexts found in kubeconfig")
+		}
+	}
+
+	for _, name := range contexts {
+		c := &Client{
+			KubeconfigPaths: kubeconfigPaths,
+			Context:         name,
+		}
+		if r.Setup != nil {
+			r.Setup(name, c)
+		}
+		if err := c.ApplyConfig(&clientConfig); err != nil {
+			return err
+		}
+		c.configLoaded = true
+		if log != nil {
+			c.Logger = log
+		}
+		// the registry decides the cluster, not the config
+		c.KubeconfigPaths = kubeconfigPaths
+		c.Context = name
+		if _, err := c.Connect(); err != nil {
+			return k8errors.Wrap("connect cluster "+name, err)
+		}
+		r.Add(name, c)
+	}
+	return nil
+}
+
+// Add will register a Client, replacing the Client with the
+// same cluster name
+//
+// Args:
+//	- cluster name
+//	- pointer to Client
+func (r *Registry) Add(cluster string, c *Client) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.clients == nil {
+		r.clients = map[string]*Client{}
+	}
+	if _, ok := r.clients[cluster]; !ok {
+		r.clusters = append(r.clusters, cluster)
+	}
+	r.clients[cluster] = c
+}
+
+// Remove will unregister the Client of a cluster
+//
+// Args:
+//	- cluster name
+func (r *Registry) Remove(cluster string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.clients[cluster]; !ok {
+		return
+	}
+	delete(r.clients, cluster)
+	for i, name := range r.clusters {
+		if name == cluster {
+			r.clusters = append(r.clusters[:i], r.clusters[i+1:]...)
+			break
+		}
+	}
+}
+
+// Get will provide the Client of a cluster
+//
+// Args:
+//	- cluster name
+//
+// Returns:
+//	- pointer to Client or error
+func (r *Registry) Get(cluster string) (*Client, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	c, ok := r.clients[cluster]
+	if !ok {
+		return nil, k8errors.New(k8errors.ErrNotFound,
+			"registry get",
+			"cluster %s not registered", cluster)
+	}
+	return c, nil
+}
+
+// Clusters will provide the cluster names in the order they
+// were registered
+//
+// Returns:
+//	- slice of string
+func (r *Registry) Clusters() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	clusters := make([]string, len(r.clusters))
+	copy(clusters, r.clusters)
+	return clusters
+}
+
+// ClusterResult is the result of a FanOut operation in a cluster
+type ClusterResult struct {
+	Cluster  string
+	Value    interface{}   // value returned by the operation
+	Err      error         // error returned by the operation
+	Duration time.Duration // time taken by the operation
+}
+
+// FanOutResults are the results of a FanOut operation, in the
+// order of Clusters()
+type FanOutResults []ClusterResult
+
+// Get will provide the result of a cluster
+//
+// Args:
+//	- cluster name
+//
+// Returns:
+//	- ClusterResult and false when the cluster is not in results
+func (results FanOutResults) Get(cluster string) (ClusterResult, bool) {
+	for _, result := range results {
+		if result.Cluster == cluster {
+			return result, true
+		}
+	}
+	return ClusterResult{}, false
+}
+
+// Err will provide the errors of all clusters as *FanOutError
+//
+// Returns:
+//	- *FanOutError or nil when all clusters succeeded
+func (results FanOutResults) Err() error {
+	fanOutErr := &FanOutError{Errors: map[string]error{}}
+	for _, result := range results {
+		if result.Err != nil {
+			fanOutErr.Errors[result.Cluster] = result.Err
+		}
+	}
+	if len(fanOutErr.Errors) == 0 {
+		return nil
+	}
+	return fanOutErr
+}
+
+// FanOutError keeps the error of every cluster that failed
+type FanOutError struct {
+	Errors map[string]error // cluster name and error
+}
+
+// Error will provide the errors sorted by cluster name
+//
+// Returns:
+//	- string
+func (e *FanOutError) Error() string {
+	clusters := make([]string, 0, len(e.Errors))
+	for cluster := range e.Errors {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	msgs := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		msgs = append(msgs, fmt.Sprintf("cluster %s: %s", cluster, e.Errors[cluster]))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is will report if the error of any cluster matches target,
+// i.e. errors.Is(err, k8errors.ErrNotFound)
+//
+// Args:
+//	- target error
+//
+// Returns:
+//	- bool
+func (e *FanOutError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// FanOut will run the operation in all clusters concurrently
+// and wait for all of them, ctx cancellation is propagated to
+// every operation. A panic in the operation is returned as the
+// cluster error.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- operation, called with the cluster name and its Client
+//
+// Returns:
+//	- FanOutResults, use Err() for the aggregated error
+func (r *Registry) FanOut(ctx context.Context,
+	fn func(ctx context.Context, cluster string, c *Client) (interface{}, error)) FanOutResults {
+
+	r.mutex.RLock()
+	clusters := make([]string, len(r.clusters))
+	copy(clusters, r.clusters)
+	clients := make([]*Client, len(clusters))
+	for i, cluster := range clusters {
+		clients[i] = r.clients[cluster]
+	}
+	r.mutex.RUnlock()
+
+	var semaphore chan struct{}
+	if r.MaxConcurrency > 0 {
+		semaphore = make(chan struct{}, r.MaxConcurrency)
+	}
+
+	results := make(FanOutResults, len(clusters))
+	var wg sync.WaitGroup
+	for i := range clusters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result := &results[i]
+			result.Cluster = clusters[i]
+
+			if semaphore != nil {
+				select {
+				case semaphore <- struct{}{}:
+					defer func() { <-semaphore }()
+				case <-ctx.Done():
+					result.Err = ctx.Err()
+					return
+				}
+			}
+
+			start := time.Now()
+			defer func() {
+				result.Duration = time.Since(start)
+				if p := recover(); p != nil {
+					result.Err = fmt.Errorf("panic: %v", p)
+				}
+			}()
+			result.Value, result.Err = fn(ctx, clusters[i], clients[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,98 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const twoClusters = `apiVersion: v1
+kind: Config
+clusters:
+- name: one
+  cluster:
+    server: https://127.0.0.1:6443
+- name: two
+  cluster:
+    server: https://127.0.0.1:6444
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: one
+  context:
+    cluster: one
+    user: admin
+- name: two
+  context:
+    cluster: two
+    user: admin
+`
+
+func setenv(t *testing.T, key string, value string) {
+	old, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestRegistryConnectSharesConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := ioutil.WriteFile(kubeconfig, []byte(twoClusters), 0600); err != nil {
+		t.Fatal(err)
+	}
+	setenv(t, EnvConfig, "off")
+	setenv(t, EnvLogOutput, filepath.Join(dir, "k8devel.log"))
+	setenv(t, EnvNamespace, "demo")
+
+	r := Registry{KubeconfigPaths: []string{kubeconfig}}
+	if err := r.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	one, _ := r.Get("one")
+	two, _ := r.Get("two")
+	if one == nil || two == nil {
+		t.Fatalf("Clusters() = %v, want one and two", r.Clusters())
+	}
+	if one.Logger == nil || one.Logger != two.Logger {
+		t.Errorf("clients don't share the Logger: %v and %v", one.Logger, two.Logger)
+	}
+	if one.Namespace != "demo" || two.Namespace != "demo" {
+		t.Errorf("Namespace = %q and %q, want demo", one.Namespace, two.Namespace)
+	}
+	if one.Context != "one" || two.Context != "two" {
+		t.Errorf("Context = %q and %q, want one and two", one.Context, two.Context)
+	}
+}
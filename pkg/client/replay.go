@@ -0,0 +1,269 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	kubernetes "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// replayQueue serves the recorded entries of the same key in
+// order, when all were served the last one is served again
+// (i.e. Poll() might ask more times than recorded)
+type replayQueue struct {
+	mutex   sync.Mutex
+	entries map[string][]JournalEntry
+	served  map[string]int
+}
+
+func newReplayQueue() *replayQueue {
+	return &replayQueue{
+		entries: map[string][]JournalEntry{},
+		served:  map[string]int{},
+	}
+}
+
+func (q *replayQueue) add(key string, entry JournalEntry) {
+	q.entries[key] = append(q.entries[key], entry)
+}
+
+func (q *replayQueue) next(key string) (JournalEntry, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	entries := q.entries[key]
+	if len(entries) == 0 {
+		return JournalEntry{}, false
+	}
+	i := q.served[key]
+	if i >= len(entries) {
+		i = len(entries) - 1
+	}
+	q.served[key] = i + 1
+	return entries[i], true
+}
+
+// ReplayTransport is a http.RoundTripper serving the responses
+// recorded by RecordingTransport, requests are matched by
+// method, path and query
+type ReplayTransport struct {
+	queue *replayQueue
+}
+
+// NewReplayTransport will create a ReplayTransport from the
+// entries of a journal
+//
+// Args:
+//	- slice of JournalEntry
+//
+// Returns:
+//	- pointer to ReplayTransport
+func NewReplayTransport(entries []JournalEntry) *ReplayTransport {
+	t := &ReplayTransport{queue: newReplayQueue()}
+	for _, entry := range entries {
+		if entry.Verb == "exec" {
+			continue
+		}
+		key, err := replayRequestKey(entry.Method, entry.URL)
+		if err != nil {
+			continue
+		}
+		t.queue.add(key, entry)
+	}
+	return t
+}
+
+// RoundTrip will serve the recorded response for the request
+//
+// Args:
+//	- http.Request
+//
+// Returns:
+//	- http.Response or error
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key, err := replayRequestKey(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := t.queue.next(key)
+	if !ok {
+		return nil, fmt.Errorf("replay: no recorded response for %s", key)
+	}
+	if entry.Status == 0 {
+		return nil, errors.New(entry.Error)
+	}
+
+	header := http.Header{}
+	if entry.ContentType != "" {
+		header.Set("Content-Type", entry.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Status, http.StatusText(entry.Status)),
+		StatusCode:    entry.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(entry.ResponseBody)),
+		ContentLength: int64(len(entry.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// replayRequestKey will provide the key to match a request,
+// the host is ignored so a journal can be replayed with any
+// Restconfig
+func replayRequestKey(method string, rawURL string) (string, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	key := method + " " + req.URL.Path
+	if req.URL.RawQuery != "" {
+		key += "?" + req.URL.RawQuery
+	}
+	return key, nil
+}
+
+// ReplayExecutor is an Executor serving the commands recorded
+// by RecordingExecutor, matched by namespace, pod and command
+type ReplayExecutor struct {
+	queue *replayQueue
+}
+
+// NewReplayExecutor will create a ReplayExecutor from the
+// entries of a journal
+//
+// Args:
+//	- slice of JournalEntry
+//
+// Returns:
+//	- pointer to ReplayExecutor
+func NewReplayExecutor(entries []JournalEntry) *ReplayExecutor {
+	e := &ReplayExecutor{queue: newReplayQueue()}
+	for _, entry := range entries {
+		if entry.Verb != "exec" {
+			continue
+		}
+		e.queue.add(replayExecKey(entry.Namespace, entry.Name, entry.Command), entry)
+	}
+	return e
+}
+
+// Exec will serve the recorded output of the command
+//
+// Args:
+//	- context for cancellation and deadline
+//	- pod name
+//	- namespace
+//	- command
+//
+// Returns:
+//	- stdout, stderr as bytes.Buffer or error
+func (e *ReplayExecutor) Exec(ctx context.Context,
+	podName string,
+	namespace string,
+	cmd []string) (bytes.Buffer, bytes.Buffer, error) {
+
+	var stdout, stderr bytes.Buffer
+
+	key := replayExecKey(namespace, podName, cmd)
+	entry, ok := e.queue.next(key)
+	if !ok {
+		return stdout, stderr, fmt.Errorf("replay: no recorded exec for %s", key)
+	}
+
+	stdout.WriteString(entry.Stdout)
+	stderr.WriteString(entry.Stderr)
+	switch {
+	case entry.ExitCode != 0:
+		return stdout, stderr, utilexec.CodeExitError{
+			Err:  errors.New(entry.Error),
+			Code: entry.ExitCode,
+		}
+	case entry.Error != "":
+		return stdout, stderr, errors.New(entry.Error)
+	}
+	return stdout, stderr, nil
+}
+
+func replayExecKey(namespace string, podName string, cmd []string) string {
+	return namespace + "/" + podName + " " + strings.Join(cmd, " ")
+}
+
+// NewReplay will create a Client serving the API requests,
+// downloads and commands recorded in a journal, no cluster
+// is required
+//
+// Args:
+//	- path of the journal file
+//
+// Returns:
+//	- pointer to Client or error
+func NewReplay(path string) (*Client, error) {
+	entries, err := ReadJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := NewReplayTransport(entries)
+	client := &Client{
+		Restconfig: &rest.Config{
+			Host:      "http://replay.local",
+			Transport: transport,
+		},
+		Namespace:  "default",
+		Executor:   NewReplayExecutor(entries),
+		HTTPClient: &http.Client{Transport: transport},
+		// No reason to wait between attempts
+		RetryPolicy: &RetryPolicy{
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+			MaxElapsedTime:  10 * time.Second,
+		},
+	}
+	client.instrument()
+
+	clientset, err := kubernetes.NewForConfig(client.rateLimited(client.Restconfig))
+	if err != nil {
+		return nil, err
+	}
+	client.Clientset = clientset
+	client.Restclientset = clientset
+	client.Dynamic, err = dynamic.NewForConfig(client.rateLimited(client.Restconfig))
+	if err != nil {
+		return nil, err
+	}
+	client.RESTMapper = newDiscoveryRESTMapper(clientset.Discovery())
+	return client, nil
+}
@@ -0,0 +1,332 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// RetryPolicy controls how Create, Delete and Wait operations
+// from all modules retry and poll the API Server
+type RetryPolicy struct {
+	InitialInterval time.Duration // First wait between attempts
+	MaxInterval     time.Duration // Upper limit for the wait between attempts
+	Multiplier      float64       // Growth of the wait after each attempt
+	Jitter          float64       // Randomization factor (0.0 - 1.0) of each wait
+	MaxElapsedTime  time.Duration // Time budget for the whole operation
+	MaxAttempts     int           // Max of attempts for Retry(), 0 means no limit
+}
+
+// DefaultRetryPolicy will provide the RetryPolicy used when
+// the Client doesn't set one
+//
+// Returns:
+//	- RetryPolicy struct
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxElapsedTime:  2 * time.Minute,
+	}
+}
+
+// TimeoutError is returned when an operation doesn't succeed
+// within the limits of the RetryPolicy
+type TimeoutError struct {
+	Operation string
+	Attempts  int
+	Elapsed   time.Duration
+	LastErr   error // last retryable error, if any
+}
+
+// Error will provide the error message
+//
+// Returns:
+//	- string
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("timed out after %d attempts (%s): %s",
+		e.Attempts,
+		e.Elapsed.Round(time.Millisecond),
+		e.Operation)
+	if e.LastErr != nil {
+		msg += ": " + e.LastErr.Error()
+	}
+	return msg
+}
+
+// Unwrap will provide the last error seen before the timeout
+//
+// Returns:
+//	- error or nil
+func (e *TimeoutError) Unwrap() error {
+	return e.LastErr
+}
+
+// Is will report TimeoutError as k8errors.ErrTimeout
+//
+// Args:
+//	- target error
+//
+// Returns:
+//	- bool
+func (e *TimeoutError) Is(target error) bool {
+	return target == k8errors.ErrTimeout
+}
+
+// IsRetryable will check if an error is transient: conflicts,
+// throttling, server timeouts, 5xx and connection errors
+//
+// Args:
+//	- error
+//
+// Returns:
+//	- bool
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, k8errors.ErrConflict) ||
+		apierrors.IsConflict(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsUnexpectedServerError(err) {
+		return true
+	}
+
+	if status := apierrors.APIStatus(nil); errors.As(err, &status) {
+		return status.Status().Code >= http.StatusInternalServerError
+	}
+
+	return utilnet.IsConnectionRefused(err) ||
+		utilnet.IsConnectionReset(err) ||
+		utilnet.IsProbableEOF(err)
+}
+
+// Retry will execute the operation until it succeeds, returns
+// a non retryable error or the RetryPolicy is exhausted
+//
+// Args:
+//	- context for cancellation and deadline
+//	- operation description, used in the errors
+//	- operation function
+//
+// Returns:
+//	- nil, error from operation wrapped as k8errors.Error or TimeoutError
+func (client *Client) Retry(ctx context.Context,
+	operation string,
+	fn func(ctx context.Context) error) error {
+
+	policy := client.retryPolicy()
+	return policy.run(ctx, operation, policy.MaxAttempts,
+		func(ctx context.Context) (bool, error) {
+			err := fn(ctx)
+			return err == nil, err
+		})
+}
+
+// Poll will check the condition until it's true, returns a non
+// retryable error or the time budget from RetryPolicy is exhausted.
+// MaxAttempts from RetryPolicy doesn't apply for Poll.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- operation description, used in the errors
+//	- condition function
+//
+// Returns:
+//	- nil, error from condition wrapped as k8errors.Error or TimeoutError
+func (client *Client) Poll(ctx context.Context,
+	operation string,
+	condition func(ctx context.Context) (bool, error)) error {
+
+	policy := client.retryPolicy()
+	return policy.run(ctx, operation, 0, condition)
+}
+
+// PollWithTimeout will check the condition like Poll, with timeout
+// as the time budget instead of MaxElapsedTime from RetryPolicy.
+// The interval and backoff are the ones of RetryPolicy.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- operation description, used in the errors
+//	- time budget, MaxElapsedTime from RetryPolicy when zero
+//	- condition function
+//
+// Returns:
+//	- nil, error from condition wrapped as k8errors.Error or TimeoutError
+func (client *Client) PollWithTimeout(ctx context.Context,
+	operation string,
+	timeout time.Duration,
+	condition func(ctx context.Context) (bool, error)) error {
+
+	policy := client.retryPolicy()
+	if timeout > 0 {
+		policy.MaxElapsedTime = timeout
+	}
+	return policy.run(ctx, operation, 0, condition)
+}
+
+// retryPolicy will provide the RetryPolicy from the Client,
+// when not set the default policy is tuned by TimeoutTaskInSec
+// (MaxInterval, the wait per attempt) and
+// NumberMaxOfAttemptsPerTask (MaxAttempts), both set give
+// MaxElapsedTime as TimeoutTaskInSec * NumberMaxOfAttemptsPerTask
+//
+// Returns:
+//	- RetryPolicy struct
+func (client *Client) retryPolicy() RetryPolicy {
+	if client.RetryPolicy != nil {
+		return *client.RetryPolicy
+	}
+
+	// TimeoutTaskInSec is the wait per attempt, as the modules
+	// slept it between attempts before RetryPolicy
+	policy := DefaultRetryPolicy()
+	perAttempt := time.Duration(client.TimeoutTaskInSec) * time.Second
+	if perAttempt > 0 {
+		policy.MaxInterval = perAttempt
+		if policy.InitialInterval > perAttempt {
+			policy.InitialInterval = perAttempt
+		}
+	}
+	if client.NumberMaxOfAttemptsPerTask > 0 {
+		policy.MaxAttempts = client.NumberMaxOfAttemptsPerTask
+		if perAttempt > 0 {
+			policy.MaxElapsedTime = perAttempt * time.Duration(client.NumberMaxOfAttemptsPerTask)
+		}
+	}
+	return policy
+}
+
+// run is the loop shared by Retry and Poll
+//
+// Args:
+//	- context for cancellation and deadline
+//	- operation description
+//	- max of attempts, 0 means no limit
+//	- attempt function returning done or error
+//
+// Returns:
+//	- nil, error from attempt or TimeoutError
+func (p RetryPolicy) run(ctx context.Context,
+	operation string,
+	maxAttempts int,
+	attempt func(ctx context.Context) (bool, error)) error {
+
+	start := time.Now()
+	interval := p.InitialInterval
+	if interval <= 0 {
+		interval = DefaultRetryPolicy().InitialInterval
+	}
+
+	var lastErr error
+	for attempts := 1; ; attempts++ {
+		done, err := attempt(ctx)
+		if done {
+			return nil
+		}
+		if err != nil {
+			if !IsRetryable(err) {
+				return k8errors.Wrap(operation, err)
+			}
+			lastErr = err
+		}
+
+		elapsed := time.Since(start)
+		remaining := p.MaxElapsedTime - elapsed
+		if (maxAttempts > 0 && attempts >= maxAttempts) ||
+			(p.MaxElapsedTime > 0 && remaining <= 0) {
+			return &TimeoutError{
+				Operation: operation,
+				Attempts:  attempts,
+				Elapsed:   elapsed,
+				LastErr:   lastErr,
+			}
+		}
+
+		wait := p.jitter(interval)
+		// API Server might ask to wait (i.e. 429 Retry-After)
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok {
+			if delay := time.Duration(seconds) * time.Second; delay > wait {
+				wait = delay
+			}
+		}
+		if p.MaxElapsedTime > 0 && wait > remaining {
+			wait = remaining
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: %w", operation, ctx.Err())
+		case <-timer.C:
+		}
+
+		interval = p.next(interval)
+	}
+}
+
+// next will provide the next interval based on Multiplier
+// and MaxInterval
+//
+// Args:
+//	- current interval
+//
+// Returns:
+//	- time.Duration
+func (p RetryPolicy) next(interval time.Duration) time.Duration {
+	if p.Multiplier > 1 {
+		interval = time.Duration(float64(interval) * p.Multiplier)
+	}
+	if p.MaxInterval > 0 && interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+	return interval
+}
+
+// jitter will randomize the interval based on Jitter
+//
+// Args:
+//	- interval
+//
+// Returns:
+//	- time.Duration
+func (p RetryPolicy) jitter(interval time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return interval
+	}
+	delta := p.Jitter * float64(interval)
+	return time.Duration(float64(interval) - delta + rand.Float64()*2*delta)
+}
@@ -0,0 +1,304 @@
+package client
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+var podsResource = schema.GroupResource{Resource: "pods"}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"conflict", apierrors.NewConflict(podsResource, "web", errors.New("modified")), true},
+		{"conflict wrapped", k8errors.Wrap("update pod web",
+			apierrors.NewConflict(podsResource, "web", errors.New("modified"))), true},
+		{"k8errors conflict", k8errors.New(k8errors.ErrConflict, "update pod web", "modified"), true},
+		{"too many requests", apierrors.NewTooManyRequests("slow down", 1), true},
+		{"server timeout", apierrors.NewServerTimeout(podsResource, "get", 1), true},
+		{"internal error", apierrors.NewInternalError(errors.New("boom")), true},
+		{"service unavailable", apierrors.NewServiceUnavailable("unavailable"), true},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"eof", io.EOF, true},
+		{"not found", apierrors.NewNotFound(podsResource, "web"), false},
+		{"already exists", apierrors.NewAlreadyExists(podsResource, "web"), false},
+		{"bad request", apierrors.NewBadRequest("bad"), false},
+		{"forbidden", apierrors.NewForbidden(podsResource, "web", errors.New("denied")), false},
+		{"other", errors.New("something else"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyRun(t *testing.T) {
+	conflict := apierrors.NewConflict(podsResource, "web", errors.New("modified"))
+	notFound := apierrors.NewNotFound(podsResource, "web")
+
+	policy := RetryPolicy{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Multiplier:      2,
+		MaxElapsedTime:  time.Minute,
+	}
+
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		maxAttempts  int
+		errs         []error // by attempt, done after the last one
+		wantAttempts int
+		wantErr      func(error) bool
+	}{
+		{
+			name:         "done at first attempt",
+			policy:       policy,
+			errs:         []error{nil},
+			wantAttempts: 1,
+		},
+		{
+			name:         "done after retryable errors",
+			policy:       policy,
+			errs:         []error{conflict, conflict, nil},
+			wantAttempts: 3,
+		},
+		{
+			name:         "not done without error is polled",
+			policy:       policy,
+			errs:         []error{nil, nil, nil},
+			wantAttempts: 3,
+		},
+		{
+			name:         "non retryable error",
+			policy:       policy,
+			errs:         []error{notFound, nil},
+			wantAttempts: 1,
+			wantErr: func(err error) bool {
+				return errors.Is(err, k8errors.ErrNotFound) && apierrors.IsNotFound(err)
+			},
+		},
+		{
+			name:         "max attempts",
+			policy:       policy,
+			maxAttempts:  2,
+			errs:         []error{conflict, conflict, nil},
+			wantAttempts: 2,
+			wantErr: func(err error) bool {
+				var timeout *TimeoutError
+				return errors.As(err, &timeout) &&
+					timeout.Attempts == 2 &&
+					timeout.LastErr == conflict
+			},
+		},
+		{
+			name: "max elapsed time",
+			policy: RetryPolicy{
+				InitialInterval: time.Millisecond,
+				MaxInterval:     time.Millisecond,
+				MaxElapsedTime:  time.Nanosecond,
+			},
+			errs:         []error{conflict, nil},
+			wantAttempts: 1,
+			wantErr: func(err error) bool {
+				return errors.Is(err, k8errors.ErrTimeout)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := tt.policy.run(context.Background(), "get pod web", tt.maxAttempts,
+				func(ctx context.Context) (bool, error) {
+					err := tt.errs[attempts]
+					attempts++
+					if attempts < len(tt.errs) {
+						return false, err
+					}
+					return err == nil, err
+				})
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != nil && (err == nil || !tt.wantErr(err)):
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{InitialInterval: time.Hour}
+
+	err := policy.run(ctx, "get pod web", 0, func(ctx context.Context) (bool, error) {
+		cancel()
+		return false, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	lastErr := apierrors.NewConflict(podsResource, "web", errors.New("modified"))
+	tests := []struct {
+		name    string
+		err     *TimeoutError
+		wantMsg string
+	}{
+		{
+			name: "without last error",
+			err: &TimeoutError{
+				Operation: "wait pod web",
+				Attempts:  3,
+				Elapsed:   1500 * time.Millisecond,
+			},
+			wantMsg: "timed out after 3 attempts (1.5s): wait pod web",
+		},
+		{
+			name: "with last error",
+			err: &TimeoutError{
+				Operation: "update pod web",
+				Attempts:  2,
+				Elapsed:   time.Second,
+				LastErr:   lastErr,
+			},
+			wantMsg: "timed out after 2 attempts (1s): update pod web: " + lastErr.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", got, tt.wantMsg)
+			}
+			if !errors.Is(tt.err, k8errors.ErrTimeout) {
+				t.Error("errors.Is(err, ErrTimeout) = false")
+			}
+			if errors.Unwrap(tt.err) != tt.err.LastErr {
+				t.Errorf("Unwrap() = %v, want %v", errors.Unwrap(tt.err), tt.err.LastErr)
+			}
+		})
+	}
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	custom := RetryPolicy{InitialInterval: time.Millisecond, MaxAttempts: 7}
+	defaults := DefaultRetryPolicy()
+
+	tests := []struct {
+		name   string
+		client *Client
+		want   RetryPolicy
+	}{
+		{
+			name:   "defaults",
+			client: &Client{},
+			want:   defaults,
+		},
+		{
+			name:   "RetryPolicy set",
+			client: &Client{RetryPolicy: &custom, TimeoutTaskInSec: 5},
+			want:   custom,
+		},
+		{
+			name:   "wait per attempt and attempts",
+			client: &Client{TimeoutTaskInSec: 2, NumberMaxOfAttemptsPerTask: 10},
+			want: RetryPolicy{
+				InitialInterval: defaults.InitialInterval,
+				MaxInterval:     2 * time.Second,
+				Multiplier:      defaults.Multiplier,
+				Jitter:          defaults.Jitter,
+				MaxElapsedTime:  20 * time.Second,
+				MaxAttempts:     10,
+			},
+		},
+		{
+			name:   "attempts only",
+			client: &Client{NumberMaxOfAttemptsPerTask: 3},
+			want: RetryPolicy{
+				InitialInterval: defaults.InitialInterval,
+				MaxInterval:     defaults.MaxInterval,
+				Multiplier:      defaults.Multiplier,
+				Jitter:          defaults.Jitter,
+				MaxElapsedTime:  defaults.MaxElapsedTime,
+				MaxAttempts:     3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.client.retryPolicy(); got != tt.want {
+				t.Errorf("retryPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientPollWithTimeout(t *testing.T) {
+	c := &Client{RetryPolicy: &RetryPolicy{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		MaxElapsedTime:  time.Nanosecond,
+	}}
+
+	// the timeout replaces MaxElapsedTime from RetryPolicy
+	attempts := 0
+	err := c.PollWithTimeout(context.Background(), "wait pod web", time.Minute,
+		func(ctx context.Context) (bool, error) {
+			attempts++
+			return attempts == 3, nil
+		})
+	if err != nil || attempts != 3 {
+		t.Errorf("PollWithTimeout() = %v after %d attempts, want nil after 3", err, attempts)
+	}
+
+	// MaxElapsedTime from RetryPolicy without timeout
+	attempts = 0
+	err = c.PollWithTimeout(context.Background(), "wait pod web", 0,
+		func(ctx context.Context) (bool, error) {
+			attempts++
+			return false, nil
+		})
+	if !errors.Is(err, k8errors.ErrTimeout) || attempts != 1 {
+		t.Errorf("PollWithTimeout() = %v after %d attempts, want ErrTimeout after 1", err, attempts)
+	}
+}
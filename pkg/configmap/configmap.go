@@ -18,13 +18,14 @@ limitations under the License.
 
 import (
 	"context"
-	"fmt"
-	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 )
 
 // Instance type refers to the ConfigMap object
@@ -39,17 +40,18 @@ type Instance struct {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.ConfigMapList or error
-func Show(c *client.Client, configmap string, namespace string) (*v1.ConfigMap, error) {
+func Show(ctx context.Context, c *client.Client, configmap string, namespace string) (*v1.ConfigMap, error) {
 	cfmap, err := c.Clientset.CoreV1().ConfigMaps(namespace).Get(
-		context.TODO(),
+		ctx,
 		configmap,
 		metav1.GetOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("get configmap "+namespace+"/"+configmap, err)
 	}
 	return cfmap, nil
 }
@@ -58,14 +60,15 @@ func Show(c *client.Client, configmap string, namespace string) (*v1.ConfigMap,
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.ConfigMapList or error
-func ListAll(c *client.Client) (*v1.ConfigMapList, error) {
-	configmap, err := c.Clientset.CoreV1().ConfigMaps("").List(context.TODO(), metav1.ListOptions{})
+func ListAll(ctx context.Context, c *client.Client) (*v1.ConfigMapList, error) {
+	configmap, err := c.Clientset.CoreV1().ConfigMaps("").List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("list configmaps", err)
 	}
 	return configmap, nil
 }
@@ -73,58 +76,78 @@ func ListAll(c *client.Client) (*v1.ConfigMapList, error) {
 // Delete deletes a configmap
 //
 // Args:
+//	ctx - context for cancellation and deadline
 //	Client - client struct from the client module
 //	configmap - ConigMap Name
 //	namespace - Namespace
 //
 //   Returns:
 //      error or nil
-func Delete(c *client.Client, configmap string, namespace string) error {
+func Delete(ctx context.Context, c *client.Client, configmap string, namespace string) error {
 	_, err := c.Clientset.CoreV1().ConfigMaps(namespace).
-		Get(context.TODO(), configmap, metav1.GetOptions{})
+		Get(ctx, configmap, metav1.GetOptions{})
 	if err != nil {
+		return k8errors.Wrap("get configmap "+namespace+"/"+configmap, err)
+	}
+
+	c.Log(logger.LevelInfo, "Deleting configmap",
+		"name", configmap,
+		"namespace", namespace)
+
+	operation := client.Operation{
+		Verb:      "delete",
+		Kind:      "ConfigMap",
+		Namespace: namespace,
+		Name:      configmap,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.CoreV1().ConfigMaps(namespace).Delete(
+			ctx,
+			configmap,
+			c.DeleteOptions())
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
-	fmt.Printf("\n")
-	fmt.Printf("Deleting configmap: %s namespace: %s...\n",
-		configmap,
-		namespace)
+	// Nothing is removed in dry-run
+	if c.IsDryRun() {
+		return nil
+	}
 
 	// Double check configmap is removed
-	// TODO: We can improve this logic with some pool schema
-	for i := 0; i < c.NumberMaxOfAttemptsPerTask; i++ {
-		_, err := Exists(c, configmap, namespace)
-		if err != nil {
-			fmt.Printf("Deleted configmap: %s namespace: %s\n",
-				configmap,
-				namespace)
-			break
+	err = c.Poll(ctx, operation.String(), func(ctx context.Context) (bool, error) {
+		_, err := Exists(ctx, c, configmap, namespace)
+		if apierrors.IsNotFound(err) {
+			return true, nil
 		}
-		c.Clientset.CoreV1().ConfigMaps(namespace).Delete(
-			context.TODO(),
-			configmap,
-			metav1.DeleteOptions{})
-
-		time.Sleep(time.Duration(c.TimeoutTaskInSec) * time.Second)
+		return false, err
+	})
+	if err != nil {
+		return err
 	}
 
+	c.Log(logger.LevelInfo, "Deleted configmap",
+		"name", configmap,
+		"namespace", namespace)
+
 	return nil
 }
 
 // Exists will check if the configmap exists or not
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, configmap string, namespace string) (string, error) {
+func Exists(ctx context.Context, c *client.Client, configmap string, namespace string) (string, error) {
 	exists, err := c.Clientset.CoreV1().ConfigMaps(namespace).
-		Get(context.TODO(), configmap, metav1.GetOptions{})
+		Get(ctx, configmap, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get configmap "+namespace+"/"+configmap, err)
 	}
 
 	return exists.Name, nil
@@ -134,12 +157,13 @@ func Exists(c *client.Client, configmap string, namespace string) (string, error
 // from the ConfigMap struct via the Client.Clientset
 //
 // Args:
+//    ctx - context for cancellation and deadline
 //    ConfigMap - ConfigMap struct
 //    Client  - Client strucut
 //
 //   Returns:
 //      error or nil
-func Create(c *client.Client, cm *Instance) error {
+func Create(ctx context.Context, c *client.Client, cm *Instance) error {
 	configmap := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cm.Name,
@@ -150,10 +174,20 @@ func Create(c *client.Client, cm *Instance) error {
 		},
 	}
 
-	_, err := c.Clientset.CoreV1().ConfigMaps(cm.Namespace).Create(
-		context.TODO(),
-		configmap,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "ConfigMap",
+		Namespace: cm.Namespace,
+		Name:      configmap.Name,
+		Object:    configmap,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().ConfigMaps(cm.Namespace).Create(
+			ctx,
+			configmap,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
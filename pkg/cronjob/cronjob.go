@@ -51,12 +51,13 @@ type Instance struct {
 // Create will create a deployment
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- Deployment from this module
 //
 // Returns:
 //	- error
-func Create(c *client.Client, i *Instance) error {
+func Create(ctx context.Context, c *client.Client, i *Instance) error {
 
 	restartPolicy, err := util.DetectContainerRestartPolicy(i.RestartPolicy)
 	concurrencyPolicy, err := util.DetectConcurrencyPolicy(i.ConcurrencyPolicy)
@@ -96,10 +97,20 @@ func Create(c *client.Client, i *Instance) error {
 	job.Spec.FailedJobsHistoryLimit = &i.FailedJobsHistoryLimit
 	job.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command = i.Command
 
-	_, err = c.Clientset.BatchV1().CronJobs(i.Namespace).Create(
-		context.TODO(),
-		job,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "CronJob",
+		Namespace: i.Namespace,
+		Name:      job.Name,
+		Object:    job,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.BatchV1().CronJobs(i.Namespace).Create(
+			ctx,
+			job,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -17,6 +17,8 @@ limitations under the License.
 */
 
 import (
+	"context"
+
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/pod"
 )
@@ -26,6 +28,7 @@ import (
 //
 // Args:
 //
+//      context for cancellation and deadline
 //      client struct
 //      container name
 //	namespace
@@ -34,14 +37,15 @@ import (
 // Returns:
 //	output as string or error
 //
-func ExecuteHTTPReqInsideContainer(c *client.Client,
+func ExecuteHTTPReqInsideContainer(ctx context.Context,
+	c *client.Client,
 	container string,
 	namespace string,
 	URL string) (string, error) {
 
 	Cmd := []string{"curl"}
 	Cmd = append(Cmd, URL)
-	stdout, _, err := pod.ExecCmd(c,
+	stdout, _, err := pod.ExecCmd(ctx, c,
 		container,
 		namespace,
 		Cmd)
@@ -45,12 +45,13 @@ type Instance struct {
 // Create will create a daemonset
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- daemonset from this module
 //
 // Returns:
 //	- error
-func Create(c *client.Client, d *Instance) error {
+func Create(ctx context.Context, c *client.Client, d *Instance) error {
 
 	podProtocol, err := util.DetectContainerPortProtocol(d.Pod.ContainerPortProtocol)
 	if err != nil {
@@ -90,10 +91,20 @@ func Create(c *client.Client, d *Instance) error {
 		},
 	}
 	// Create Daemonset
-	_, err = c.Clientset.AppsV1().DaemonSets(d.Namespace).Create(
-		context.TODO(),
-		daemonset,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "DaemonSet",
+		Namespace: d.Namespace,
+		Name:      daemonset.Name,
+		Object:    daemonset,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.AppsV1().DaemonSets(d.Namespace).Create(
+			ctx,
+			daemonset,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -17,228 +17,203 @@ limitations under the License.
 */
 
 import (
-	"bytes"
 	"context"
-	"fmt"
+	"time"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
-	appsv1 "k8s.io/api/apps/v1"
-	v1 "k8s.io/api/core/v1"
-	v1beta1 "k8s.io/api/policy/v1beta1"
-	rbacv1 "k8s.io/api/rbac/v1"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes/scheme"
 )
 
-const yamlDelimiter = "---"
+// Options controls how the objects are deleted
+type Options struct {
+	// PropagationPolicy for the dependents of the objects:
+	// metav1.DeletePropagationForeground, Background or Orphan.
+	// The default of the kind when empty.
+	PropagationPolicy metav1.DeletionPropagation
 
-// decode will Decode data to object
+	// GracePeriodSeconds overrides the grace period of the
+	// objects, 0 deletes immediately. The default of the object
+	// when nil.
+	GracePeriodSeconds *int64
+
+	// Wait for the objects to be gone: finalizers done,
+	// dependents deleted with Foreground and Namespaces out of
+	// Terminating. Objects not gone are reported with ErrTimeout
+	// and the reason (i.e. the namespace conditions). Not done in
+	// dry-run.
+	Wait bool
+
+	// WaitTimeout is the time for all objects to be gone,
+	// DefaultWaitTimeout when zero
+	WaitTimeout time.Duration
+}
+
+// deleteOptions will provide the metav1.DeleteOptions of the
+// Options and the client DryRun mode
+func (o Options) deleteOptions(c *client.Client) metav1.DeleteOptions {
+	opts := c.DeleteOptions()
+	if len(o.PropagationPolicy) > 0 {
+		policy := o.PropagationPolicy
+		opts.PropagationPolicy = &policy
+	}
+	opts.GracePeriodSeconds = o.GracePeriodSeconds
+	return opts
+}
+
+// YAML will go by the read object and delete it via API, any
+// kind served by the cluster (custom resources included) is
+// supported. Namespaced objects without namespace are deleted
+// from the client Namespace.
 //
 // Args:
-//     - date []byte
+//	- context for cancellation and deadline
+//	- client struct
+//	- yamlInput []bytes
 //
 // Returns:
-//     runtime.Object or error
+//	- one line per object, i.e. "namespace foo deleted"
 //
-func decode(data []byte) (runtime.Object, error) {
-	decode := scheme.Codecs.UniversalDeserializer().Decode
-	obj, _, err := decode(data, nil, nil)
-	return obj, err
+func YAML(ctx context.Context, c *client.Client, yamlInput []byte) []string {
+	var output []string
+	for _, result := range YAMLResults(ctx, c, yamlInput) {
+		output = append(output, result.String())
+	}
+	return output
 }
 
-// YAML will go by the read object and delete it via API
+// YAMLResults will delete the objects from the YAML or JSON
+// manifest like YAML() and provide the outcome of each document
 //
 // Args:
-//      - client struct
+//	- context for cancellation and deadline
+//	- client struct
 //	- yamlInput []bytes
 //
 // Returns:
-//	- None
+//	- Result per document in the manifest order, see Results.Err()
 //
-// TODO: Can we simplify the switch?
-func YAML(c *client.Client, yamlInput []byte) []string {
-	var output []string
-	yamlFiles := bytes.Split(yamlInput, []byte(yamlDelimiter))
+func YAMLResults(ctx context.Context, c *client.Client, yamlInput []byte) manifest.Results {
+	return Documents(ctx, c, manifest.Parse(yamlInput))
+}
 
-	for _, f := range yamlFiles {
-		if len(f) == 0 || string(f) == "\n" {
-			continue
-		}
+// YAMLWithOptions will delete the objects from the YAML or JSON
+// manifest like YAMLResults() with Options
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- yamlInput []bytes
+//	- Options
+//
+// Returns:
+//	- Result per document in the manifest order, see Results.Err()
+//
+func YAMLWithOptions(ctx context.Context,
+	c *client.Client,
+	yamlInput []byte,
+	opts Options) manifest.Results {
+
+	return DocumentsWithOptions(ctx, c, manifest.Parse(yamlInput), opts)
+}
 
-		obj, err := decode(f)
-		if err != nil {
+// Documents will delete the objects read by the manifest
+// package, i.e. from files or directories with manifest.ReadDir(),
+// in the reverse of manifest.KindOrder
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- slice of manifest.Document
+//
+// Returns:
+//	- Result per document in the manifest order, see Results.Err()
+//
+func Documents(ctx context.Context, c *client.Client, docs []manifest.Document) manifest.Results {
+	return DocumentsWithOptions(ctx, c, docs, Options{})
+}
+
+// DocumentsWithOptions will delete the objects like Documents()
+// with Options. With Options.Wait it returns when all objects are
+// gone or WaitTimeout expires.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- slice of manifest.Document
+//	- Options
+//
+// Returns:
+//	- Result per document in the manifest order, see Results.Err()
+//
+func DocumentsWithOptions(ctx context.Context,
+	c *client.Client,
+	docs []manifest.Document,
+	opts Options) manifest.Results {
+
+	ordered := make([]manifest.Document, len(docs))
+	copy(ordered, docs)
+	manifest.SortForDelete(ordered)
+
+	var results manifest.Results
+	for _, doc := range ordered {
+		if doc.Err != nil {
+			results = append(results, doc.Result())
 			continue
 		}
+		results = append(results, deleteObject(ctx, c, doc, opts))
+	}
+	results.ByIndex()
 
-		switch obj.(type) {
-		case *v1.ServiceAccount:
-			// If namespace not declared, use default
-			namespace := ""
-			if len(obj.(*v1.ServiceAccount).Namespace) == 0 {
-				namespace = "default"
-			} else {
-				namespace = obj.(*v1.ServiceAccount).Namespace
-			}
-			err = c.Clientset.CoreV1().ServiceAccounts(namespace).Delete(
-				context.TODO(),
-				obj.(*v1.ServiceAccount).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("service ",
-						obj.(*v1.ServiceAccount).Name,
-						" deleted"))
-			}
-		case *v1.Namespace:
-			err = c.Clientset.CoreV1().Namespaces().Delete(
-				context.TODO(),
-				obj.(*v1.Namespace).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("namespace ",
-						obj.(*v1.Namespace).Name,
-						" deleted"))
-			}
-		case *v1.ConfigMap:
-			err = c.Clientset.CoreV1().ConfigMaps(obj.(*v1.ConfigMap).Namespace).Delete(
-				context.TODO(),
-				obj.(*v1.ConfigMap).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("configmap ",
-						obj.(*v1.ConfigMap).Name,
-						" deleted"))
-			}
-		case *appsv1.Deployment:
-			err = c.Clientset.AppsV1().Deployments(obj.(*appsv1.Deployment).Namespace).Delete(
-				context.TODO(),
-				obj.(*appsv1.Deployment).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("deployment ",
-						obj.(*appsv1.Deployment).Name,
-						" deleted"))
-			}
-		case *appsv1.DaemonSet:
-			err = c.Clientset.AppsV1().DaemonSets(obj.(*appsv1.DaemonSet).Namespace).Delete(
-				context.TODO(),
-				obj.(*appsv1.DaemonSet).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("daemonset ",
-						obj.(*appsv1.DaemonSet).Name,
-						" deleted"))
-			}
-		case *appsv1.StatefulSet:
-			err = c.Clientset.AppsV1().StatefulSets(obj.(*appsv1.StatefulSet).Namespace).Delete(
-				context.TODO(),
-				obj.(*appsv1.StatefulSet).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("statefulset ",
-						obj.(*appsv1.StatefulSet).Name,
-						" deleted"))
-			}
-		case *v1beta1.PodSecurityPolicy:
-			err = c.Clientset.PolicyV1beta1().PodSecurityPolicies().Delete(
-				context.TODO(),
-				obj.(*v1beta1.PodSecurityPolicy).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("podsecuritypolicy ",
-						obj.(*v1beta1.PodSecurityPolicy).Name,
-						" deleted"))
-			}
-		case *rbacv1.ClusterRole:
-			err = c.Clientset.RbacV1().ClusterRoles().Delete(
-				context.TODO(),
-				obj.(*rbacv1.ClusterRole).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("clusterrole ",
-						obj.(*rbacv1.ClusterRole).Name,
-						" deleted"))
-			}
-		case *rbacv1.RoleBinding:
-			err = c.Clientset.RbacV1().RoleBindings(obj.(*rbacv1.RoleBinding).Namespace).Delete(
-				context.TODO(),
-				obj.(*rbacv1.RoleBinding).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("rolebinding ",
-						obj.(*rbacv1.RoleBinding).Name,
-						" deleted"))
-			}
-		case *rbacv1.ClusterRoleBinding:
-			err = c.Clientset.RbacV1().ClusterRoleBindings().Delete(
-				context.TODO(),
-				obj.(*rbacv1.ClusterRoleBinding).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("clusterrolebinding ",
-						obj.(*rbacv1.ClusterRoleBinding).Name,
-						" deleted"))
-			}
-		case *rbacv1.Role:
-			err = c.Clientset.RbacV1().Roles(obj.(*rbacv1.Role).Namespace).Delete(
-				context.TODO(),
-				obj.(*rbacv1.Role).Name,
-				metav1.DeleteOptions{})
-			if err != nil {
-				output = append(output, fmt.Sprint(err))
-			} else {
-				output = append(
-					output,
-					fmt.Sprint("role ",
-						obj.(*rbacv1.Role).Name,
-						" deleted"))
-			}
-		default:
-			output = append(
-				output,
-				"error, unknown object kind for applying, verify yaml provided...")
+	// nothing deleted in dry-run
+	if opts.Wait && !c.IsDryRun() {
+		timeout := opts.WaitTimeout
+		if timeout == 0 {
+			timeout = DefaultWaitTimeout
 		}
+		waitForDeletion(ctx, c, results, timeout)
+	}
+	return results
+}
+
+// deleteObject will delete the object of the document, a
+// Namespace already Terminating is reported as deleted
+func deleteObject(ctx context.Context,
+	c *client.Client,
+	doc manifest.Document,
+	opts Options) manifest.Result {
 
+	obj := doc.Object
+	result := doc.Result()
+	result.DryRun = c.DryRun
+
+	ri, _, err := c.ResourceFor(obj)
+	if err != nil {
+		result.Action = manifest.ActionFailed
+		result.Err = err
+		return result
 	}
-	return output
+	result.Namespace = obj.GetNamespace()
+
+	err = c.Do(ctx, client.Operation{
+		Verb:      "delete",
+		Kind:      result.GVK.Kind,
+		Namespace: result.Namespace,
+		Name:      result.Name,
+	}, func(ctx context.Context) error {
+		err := ri.Delete(ctx, result.Name, opts.deleteOptions(c))
+		if apierrors.IsConflict(err) && isNamespace(result.GVK) &&
+			terminating(ctx, ri, result.Name) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		result.Action = manifest.ActionFailed
+		result.Err = err
+		return result
+	}
+	result.Action = manifest.ActionDeleted
+	return result
 }
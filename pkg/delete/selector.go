@@ -0,0 +1,168 @@
+package delete
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"strings"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// DeleteBySelector will delete the objects of the kinds matching
+// the label selector, like kubectl delete -l. The objects are
+// deleted in the reverse of manifest.KindOrder, see
+// DocumentsWithOptions().
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- kinds as in kubectl: Deployment, deployments,
+//	  deployments.apps or deployments.v1.apps
+//	- namespace of namespaced kinds, the client Namespace when
+//	  empty
+//	- label selector, i.e. app=web,tier!=db, required
+//	- Options
+//
+// Returns:
+//	- Result per object found, in the order of the kinds, and per
+//	  kind that can't be listed, see Results.Err()
+//
+func DeleteBySelector(ctx context.Context,
+	c *client.Client,
+	kinds []string,
+	namespace string,
+	selector string,
+	opts Options) manifest.Results {
+
+	var docs []manifest.Document
+	fail := func(err error) {
+		docs = append(docs, manifest.Document{Index: len(docs), Err: err})
+	}
+
+	op := "delete by selector " + selector
+	if len(strings.TrimSpace(selector)) == 0 {
+		fail(k8errors.New(k8errors.ErrInvalidSpec, op,
+			"label selector is required"))
+		return DocumentsWithOptions(ctx, c, docs, opts)
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		fail(k8errors.New(k8errors.ErrInvalidSpec, op, "%s", err))
+		return DocumentsWithOptions(ctx, c, docs, opts)
+	}
+
+	for _, kind := range kinds {
+		objects, err := listBySelector(ctx, c, kind, namespace, selector)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		for i := range objects {
+			docs = append(docs, manifest.Document{
+				Index:  len(docs),
+				Object: &objects[i],
+			})
+		}
+	}
+	return DocumentsWithOptions(ctx, c, docs, opts)
+}
+
+// listBySelector will list the objects of the kind matching the
+// label selector
+func listBySelector(ctx context.Context,
+	c *client.Client,
+	kind string,
+	namespace string,
+	selector string) ([]unstructured.Unstructured, error) {
+
+	gvk, err := kindFor(c, kind)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := c.RESTMapping(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if c.Dynamic == nil {
+		return nil, k8errors.New(k8errors.ErrNotConnected, "list "+kind,
+			"Connect() must be called before")
+	}
+
+	var ri dynamic.ResourceInterface = c.Dynamic.Resource(mapping.Resource)
+	op := "list " + mapping.Resource.Resource
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if len(namespace) == 0 {
+			namespace = c.Namespace
+		}
+		if len(namespace) == 0 {
+			namespace = metav1.NamespaceDefault
+		}
+		op += " in " + namespace
+		ri = c.Dynamic.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	var list *unstructured.UnstructuredList
+	err = c.Retry(ctx, op, func(ctx context.Context) error {
+		var err error
+		list, err = ri.List(ctx, metav1.ListOptions{LabelSelector: selector})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		// items of a list might not have them
+		list.Items[i].SetGroupVersionKind(gvk)
+	}
+	return list.Items, nil
+}
+
+// kindFor will provide the kind of a resource as accepted by
+// kubectl, singular and plural names included
+func kindFor(c *client.Client, kind string) (schema.GroupVersionKind, error) {
+	op := "kind for " + kind
+	if c.RESTMapper == nil {
+		return schema.GroupVersionKind{}, k8errors.New(k8errors.ErrNotConnected, op,
+			"Connect() must be called before")
+	}
+
+	gvr, gr := schema.ParseResourceArg(strings.ToLower(kind))
+	if gvr != nil {
+		if gvk, err := c.RESTMapper.KindFor(*gvr); err == nil {
+			return gvk, nil
+		}
+	}
+	// the preferred version when served by several groups, i.e.
+	// deployments from apps and extensions
+	gvks, err := c.RESTMapper.KindsFor(gr.WithVersion(""))
+	if meta.IsNoMatchError(err) || (err == nil && len(gvks) == 0) {
+		return schema.GroupVersionKind{}, k8errors.New(k8errors.ErrUnsupportedKind, op,
+			"kind %s is not served by the cluster", kind)
+	}
+	if err != nil {
+		return schema.GroupVersionKind{}, k8errors.Wrap(op, err)
+	}
+	return gvks[0], nil
+}
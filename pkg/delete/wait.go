@@ -0,0 +1,126 @@
+package delete
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/manifest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultWaitTimeout is the time for all objects to be gone when
+// Options.WaitTimeout is not set
+const DefaultWaitTimeout = 5 * time.Minute
+
+// isNamespace will report if the kind is Namespace
+func isNamespace(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
+
+// terminating will report if the Namespace is being deleted,
+// deleting it again is a conflict
+func terminating(ctx context.Context, ri dynamic.ResourceInterface, name string) bool {
+	ns, err := ri.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	phase, _, _ := unstructured.NestedString(ns.Object, "status", "phase")
+	return phase == "Terminating"
+}
+
+// waitForDeletion will wait the objects deleted to be gone, the
+// results of the objects still there after the timeout are set
+// with ErrTimeout
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- Results, in the manifest order
+//	- timeout for all objects
+func waitForDeletion(ctx context.Context,
+	c *client.Client,
+	results manifest.Results,
+	timeout time.Duration) {
+
+	var pending []*manifest.Result
+	for i := range results {
+		if results[i].Err == nil && results[i].Action == manifest.ActionDeleted {
+			pending = append(pending, &results[i])
+		}
+	}
+
+	notGone := manifest.Wait(ctx, c, pending, timeout, func(ctx context.Context,
+		r *manifest.Result,
+		obj *unstructured.Unstructured,
+		err error) (bool, string, error) {
+
+		switch {
+		case apierrors.IsNotFound(err):
+			return true, "", nil
+		case err != nil:
+			return false, err.Error(), nil
+		}
+		return false, notGoneReason(obj), nil
+	})
+
+	for _, w := range notGone {
+		op := "wait " + strings.ToLower(w.Result.GVK.Kind) + " " + w.Result.Object() + " deleted"
+		w.Result.Err = k8errors.New(k8errors.ErrTimeout, op,
+			"still there after %s: %s", timeout, w.Reason)
+	}
+}
+
+// notGoneReason will explain why the object still exists: the
+// conditions of a Terminating namespace (content or finalizers
+// remaining) or the finalizers of the object
+func notGoneReason(obj *unstructured.Unstructured) string {
+	var reasons []string
+	if isNamespace(obj.GroupVersionKind()) {
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["status"] != "True" {
+				continue
+			}
+			if message, ok := condition["message"].(string); ok && len(message) > 0 {
+				reasons = append(reasons, message)
+			}
+		}
+		if finalizers, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "finalizers"); len(finalizers) > 0 {
+			reasons = append(reasons, "namespace finalizers: "+strings.Join(finalizers, ", "))
+		}
+	}
+	if finalizers := obj.GetFinalizers(); len(finalizers) > 0 {
+		reasons = append(reasons, "finalizers: "+strings.Join(finalizers, ", "))
+	}
+	if len(reasons) > 0 {
+		return strings.Join(reasons, "; ")
+	}
+	if obj.GetDeletionTimestamp() == nil {
+		return "not being deleted"
+	}
+	return "being deleted"
+}
@@ -18,14 +18,15 @@ limitations under the License.
 
 import (
 	"context"
-	"fmt"
-	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
 
@@ -48,12 +49,13 @@ type Instance struct {
 // Create will create a deployment
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- Deployment from this module
 //
 // Returns:
 //	- error
-func Create(c *client.Client, d *Instance) error {
+func Create(ctx context.Context, c *client.Client, d *Instance) error {
 
 	deployClient := c.Clientset.AppsV1().Deployments(d.Namespace)
 	podProtocol, err := util.DetectContainerPortProtocol(d.Pod.ContainerPortProtocol)
@@ -98,10 +100,20 @@ func Create(c *client.Client, d *Instance) error {
 	}
 
 	// Create Deployment
-	_, err = deployClient.Create(
-		context.TODO(),
-		deployment,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Deployment",
+		Namespace: d.Namespace,
+		Name:      deployment.Name,
+		Object:    deployment,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := deployClient.Create(
+			ctx,
+			deployment,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -111,46 +123,67 @@ func Create(c *client.Client, d *Instance) error {
 // Delete will delete an deployment
 //
 // Args:
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //      - deployment name
 //      - namespace
 // Return:
 //      - error or nil
-func Delete(c *client.Client, deployment string, namespace string) error {
+func Delete(ctx context.Context, c *client.Client, deployment string, namespace string) error {
 	_, err := c.Clientset.AppsV1().Deployments(namespace).
-		Get(context.TODO(), deployment, metav1.GetOptions{})
+		Get(ctx, deployment, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return k8errors.Wrap("get deployment "+namespace+"/"+deployment, err)
 	}
 
-	fmt.Printf("Deleting deployment: %s namespace: %s...\n",
-		deployment,
-		namespace)
-
-	// Double check service is removed
-	// TODO: We can improve this logic with some pool schema
-	for i := 0; i < c.NumberMaxOfAttemptsPerTask; i++ {
-		_, err := Exists(c, deployment, namespace)
-		if err != nil {
-			fmt.Printf("Deleted deployment: %s namespace: %s\n",
-				deployment,
-				namespace)
-			break
-		}
-		c.Clientset.AppsV1().Deployments(namespace).Delete(
-			context.TODO(),
+	c.Log(logger.LevelInfo, "Deleting deployment",
+		"name", deployment,
+		"namespace", namespace)
+
+	operation := client.Operation{
+		Verb:      "delete",
+		Kind:      "Deployment",
+		Namespace: namespace,
+		Name:      deployment,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.AppsV1().Deployments(namespace).Delete(
+			ctx,
 			deployment,
-			metav1.DeleteOptions{})
+			c.DeleteOptions())
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
 
-		time.Sleep(time.Duration(c.TimeoutTaskInSec) * time.Second)
+	// Nothing is removed in dry-run
+	if c.IsDryRun() {
+		return nil
 	}
 
+	// Double check deployment is removed
+	err = c.Poll(ctx, operation.String(), func(ctx context.Context) (bool, error) {
+		_, err := Exists(ctx, c, deployment, namespace)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Log(logger.LevelInfo, "Deleted deployment",
+		"name", deployment,
+		"namespace", namespace)
+
 	return nil
 }
 
 // Exists will check if the service exists or not
 //
 // Args:
+// 	context for cancellation and deadline
 // 	Pointer to a Client struct
 //	Service Name
 //	Namespace
@@ -158,11 +191,11 @@ func Delete(c *client.Client, deployment string, namespace string) error {
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, deployment string, namespace string) (string, error) {
+func Exists(ctx context.Context, c *client.Client, deployment string, namespace string) (string, error) {
 	exists, err := c.Clientset.AppsV1().Deployments(namespace).
-		Get(context.TODO(), deployment, metav1.GetOptions{})
+		Get(ctx, deployment, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get deployment "+namespace+"/"+deployment, err)
 	}
 
 	return exists.Name, nil
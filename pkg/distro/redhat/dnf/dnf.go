@@ -17,6 +17,8 @@ limitations under the License.
 */
 
 import (
+	"context"
+
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/pod"
 )
@@ -26,6 +28,7 @@ import (
 //
 // Args:
 //
+//      context for cancellation and deadline
 //      client struct
 //      container name
 //	namespace
@@ -33,12 +36,13 @@ import (
 // Returns:
 //	output as string or error
 //
-func UpdateInsidePod(c *client.Client,
+func UpdateInsidePod(ctx context.Context,
+	c *client.Client,
 	container string,
 	namespace string) (string, error) {
 
 	Cmd := []string{"dnf", "update", "-y"}
-	stdout, _, err := pod.ExecCmd(c,
+	stdout, _, err := pod.ExecCmd(ctx, c,
 		container,
 		namespace,
 		Cmd)
@@ -54,6 +58,7 @@ func UpdateInsidePod(c *client.Client,
 //
 // Args:
 //
+//      context for cancellation and deadline
 //      client struct
 //      container name
 //	namespace
@@ -62,7 +67,8 @@ func UpdateInsidePod(c *client.Client,
 // Returns:
 //	output as string or error
 //
-func InstallPackageInsidePod(c *client.Client,
+func InstallPackageInsidePod(ctx context.Context,
+	c *client.Client,
 	container string,
 	namespace string,
 	packagename string) (string, error) {
@@ -70,7 +76,7 @@ func InstallPackageInsidePod(c *client.Client,
 	Cmd := []string{"dnf", "install", "-y"}
 	Cmd = append(Cmd, packagename)
 
-	stdout, _, err := pod.ExecCmd(c,
+	stdout, _, err := pod.ExecCmd(ctx, c,
 		container,
 		namespace,
 		Cmd)
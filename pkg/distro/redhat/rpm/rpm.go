@@ -17,6 +17,8 @@ limitations under the License.
 */
 
 import (
+	"context"
+
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/pod"
 )
@@ -25,6 +27,7 @@ import (
 //
 // Args:
 //
+//      context for cancellation and deadline
 //      client struct
 //      container name
 //	namespace
@@ -33,13 +36,14 @@ import (
 // Returns:
 //	output as string or error
 //
-func CheckPackageInstalled(c *client.Client,
+func CheckPackageInstalled(ctx context.Context,
+	c *client.Client,
 	container string,
 	namespace string,
 	packagename string) (string, error) {
 
 	Cmd := []string{"rpm", "-q", packagename}
-	stdout, _, err := pod.ExecCmd(c,
+	stdout, _, err := pod.ExecCmd(ctx, c,
 		container,
 		namespace,
 		Cmd)
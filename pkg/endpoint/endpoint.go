@@ -19,15 +19,16 @@ limitations under the License.
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
-	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
 
@@ -48,39 +49,40 @@ type Instance struct {
 // List will list ALL endpoints from a namespace
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- Instance from endpoint module
 //
 // Return:
-//	- error or nil
-func List(c *client.Client, e *Instance) {
-	epoints, _ := c.Clientset.CoreV1().Endpoints(e.Namespace).List(context.TODO(), metav1.ListOptions{})
-	fmt.Printf("\n")
-	fmt.Printf("Listing endpoints in namespace %s:\n", e.Namespace)
-	for _, ep := range epoints.Items {
-		fmt.Printf("\tName: " + ep.Name)
+//	- pointer v1.EndpointsList or error
+func List(ctx context.Context, c *client.Client, e *Instance) (*v1.EndpointsList, error) {
+	c.Log(logger.LevelInfo, "Listing endpoints", "namespace", e.Namespace)
+	epoints, err := c.Clientset.CoreV1().Endpoints(e.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, k8errors.Wrap("list endpoints "+e.Namespace, err)
 	}
+	return epoints, nil
 }
 
 // Patch will patch an endpoint object
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- Instance from endpoint module
 //
 // Return:
 //	- error or nil
-func Patch(c *client.Client, e *Instance) error {
-	fmt.Printf("\n")
-	fmt.Printf("Patching endpoint: %s namespace: %s\n",
-		e.Name,
-		e.Namespace)
+func Patch(ctx context.Context, c *client.Client, e *Instance) error {
+	c.Log(logger.LevelInfo, "Patching endpoint",
+		"name", e.Name,
+		"namespace", e.Namespace)
 	_, err := c.Clientset.CoreV1().Endpoints(e.Namespace).Get(
-		context.TODO(),
+		ctx,
 		e.Name,
 		metav1.GetOptions{})
 	if err != nil {
-		return err
+		return k8errors.Wrap("get endpoints "+e.Namespace+"/"+e.Name, err)
 	}
 
 	endpointPatch, err := json.Marshal(map[string]interface{}{
@@ -111,37 +113,47 @@ func Patch(c *client.Client, e *Instance) error {
 	}
 
 	// Executing the patch
-	_, err = c.Clientset.CoreV1().Endpoints(e.Namespace).Patch(
-		context.TODO(),
-		e.Name,
-		types.StrategicMergePatchType,
-		[]byte(endpointPatch),
-		metav1.PatchOptions{})
+	operation := client.Operation{
+		Verb:      "patch",
+		Kind:      "Endpoints",
+		Namespace: e.Namespace,
+		Name:      e.Name,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Endpoints(e.Namespace).Patch(
+			ctx,
+			e.Name,
+			types.StrategicMergePatchType,
+			[]byte(endpointPatch),
+			c.PatchOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Patched endpoint: %s namespace %s\n", e.Name, e.Namespace)
+	c.Log(logger.LevelInfo, "Patched endpoint",
+		"name", e.Name,
+		"namespace", e.Namespace)
 	return nil
 }
 
 // Create will create an endpoint
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- Instance from endpoint module
 //
 // Return:
 //	- error or nil
-func Create(c *client.Client, e *Instance) error {
-	fmt.Printf("\n")
-	fmt.Printf("Creating endpoint: %s namespace: %s\n",
-		e.Name,
-		e.Namespace)
+func Create(ctx context.Context, c *client.Client, e *Instance) error {
+	c.Log(logger.LevelInfo, "Creating endpoint",
+		"name", e.Name,
+		"namespace", e.Namespace)
 
 	proto, err := util.DetectContainerPortProtocol(e.EndpointPort.Protocol)
 	if err != nil {
-		fmt.Printf("%s\n", err)
 		return err
 	}
 
@@ -166,37 +178,49 @@ func Create(c *client.Client, e *Instance) error {
 			},
 		},
 	}
-	_, err = c.Clientset.CoreV1().Endpoints(e.Namespace).Create(
-		context.TODO(),
-		epoints,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Endpoints",
+		Namespace: e.Namespace,
+		Name:      epoints.Name,
+		Object:    epoints,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Endpoints(e.Namespace).Create(
+			ctx,
+			epoints,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Created endpoint: %s\n", e.Name)
+	c.Log(logger.LevelInfo, "Created endpoint",
+		"name", e.Name,
+		"namespace", e.Namespace)
 	return nil
 }
 
 // Show will display a specific endpoint
 // Args:
-// 	- Client struct from client module
+//	- context for cancellation and deadline
+//	- Client struct from client module
 //	- endpoint name
-func Show(c *client.Client, endpoint string, namespace string) error {
+func Show(ctx context.Context, c *client.Client, endpoint string, namespace string) error {
 	epoints, err := c.Clientset.CoreV1().Endpoints(namespace).Get(
-		context.TODO(),
+		ctx,
 		endpoint,
 		metav1.GetOptions{})
 	if err != nil {
-		return err
+		return k8errors.Wrap("get endpoints "+namespace+"/"+endpoint, err)
 	}
 
-	fmt.Printf("\n")
-	fmt.Printf("Showing information about endpoint: %s namespace: %s\n",
-		endpoint,
-		namespace)
+	c.Log(logger.LevelInfo, "Showing information about endpoint",
+		"name", endpoint,
+		"namespace", namespace)
 
 	if len(epoints.Subsets) == 0 {
-		fmt.Printf("Subsets: []")
+		c.Log(logger.LevelInfo, "Subsets: []")
 	}
 
 	port := ""
@@ -204,14 +228,16 @@ func Show(c *client.Client, endpoint string, namespace string) error {
 		port = strconv.FormatInt(int64(epoints.Subsets[0].Ports[0].Port), 10)
 		for _, p := range epoints.Subsets[0].Ports {
 			if p.Name != "" {
-				fmt.Printf("\tEndpointPort Name: %s\n", p.Name)
-				fmt.Printf("\tEndpointPort Port: %s\n", strconv.FormatInt(int64(p.Port), 10))
+				c.Log(logger.LevelInfo, "EndpointPort",
+					"name", p.Name,
+					"port", strconv.FormatInt(int64(p.Port), 10))
 			}
 		}
 
 		for _, address := range epoints.Subsets[0].Addresses {
-			fmt.Printf("\tEndpointAddress: %s\n", address.IP)
-			fmt.Printf("\tEndpointAddress Port: %s\n", port)
+			c.Log(logger.LevelInfo, "EndpointAddress",
+				"ip", address.IP,
+				"port", port)
 		}
 	}
 
@@ -221,62 +247,82 @@ func Show(c *client.Client, endpoint string, namespace string) error {
 // Delete will delete an endpoint
 //
 // Args:
-// 	- Client struct from client module
+//	- context for cancellation and deadline
+//	- Client struct from client module
 //	- endpoint name
 //	- namespace
 // Return:
 //	- error or nil
-func Delete(c *client.Client, endpoint string, namespace string) error {
+func Delete(ctx context.Context, c *client.Client, endpoint string, namespace string) error {
 	inst := Instance{Name: endpoint, Namespace: namespace}
 
 	_, err := c.Clientset.CoreV1().Endpoints(inst.Namespace).
-		Get(context.TODO(), inst.Name, metav1.GetOptions{})
+		Get(ctx, inst.Name, metav1.GetOptions{})
 	if err != nil {
+		return k8errors.Wrap("get endpoints "+inst.Namespace+"/"+inst.Name, err)
+	}
+
+	c.Log(logger.LevelInfo, "Deleting endpoint",
+		"name", inst.Name,
+		"namespace", inst.Namespace)
+
+	operation := client.Operation{
+		Verb:      "delete",
+		Kind:      "Endpoints",
+		Namespace: inst.Namespace,
+		Name:      inst.Name,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.CoreV1().Endpoints(inst.Namespace).Delete(
+			ctx,
+			inst.Name,
+			c.DeleteOptions())
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
-	fmt.Printf("\n")
-	fmt.Printf("Deleting endpoint: %s namespace: %s...\n",
-		inst.Name,
-		inst.Namespace)
+	// Nothing is removed in dry-run
+	if c.IsDryRun() {
+		return nil
+	}
 
 	// Double check endpoint is removed
-	// TODO: We can improve this logic with some pool schema
-	for i := 0; i < c.NumberMaxOfAttemptsPerTask; i++ {
-		_, err := Exists(c, &inst)
-		if err != nil {
-			fmt.Printf("Deleted endpoint: %s namespace: %s\n",
-				inst.Name,
-				inst.Namespace)
-			break
+	err = c.Poll(ctx, operation.String(), func(ctx context.Context) (bool, error) {
+		_, err := Exists(ctx, c, &inst)
+		if apierrors.IsNotFound(err) {
+			return true, nil
 		}
-		c.Clientset.CoreV1().Endpoints(inst.Namespace).Delete(
-			context.TODO(),
-			inst.Name,
-			metav1.DeleteOptions{})
-
-		time.Sleep(time.Duration(c.TimeoutTaskInSec) * time.Second)
+		return false, err
+	})
+	if err != nil {
+		return err
 	}
 
+	c.Log(logger.LevelInfo, "Deleted endpoint",
+		"name", inst.Name,
+		"namespace", inst.Namespace)
+
 	return nil
 }
 
 // Exists will check if the endpoint exists or not
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- Instance struct from this module
 //
 // Returns:
 //     bool OR error type
 //
-func Exists(c *client.Client, e *Instance) (string, error) {
+func Exists(ctx context.Context, c *client.Client, e *Instance) (string, error) {
 	exists, err := c.Clientset.CoreV1().Endpoints(e.Namespace).Get(
-		context.TODO(),
+		ctx,
 		e.Name,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get endpoints "+e.Namespace+"/"+e.Name, err)
 	}
 
 	return exists.Name, nil
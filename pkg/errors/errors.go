@@ -0,0 +1,172 @@
+package errors
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Sentinel errors, use errors.Is to check the kind of an error
+// returned by any module, example:
+//	if errors.Is(err, k8errors.ErrNotFound) {
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrTimeout         = errors.New("timeout")
+	ErrInvalidSpec     = errors.New("invalid spec")
+	ErrUnsupportedKind = errors.New("unsupported kind")
+	ErrConflict        = errors.New("conflict")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotConnected    = errors.New("client not connected")
+	ErrNotReady        = errors.New("not ready")
+)
+
+// Error is the error returned by the modules, it keeps the
+// kind (one of the sentinel errors), the operation and the
+// underlying error (i.e. from API Server)
+type Error struct {
+	Kind error  // Sentinel error, nil when unknown
+	Op   string // Operation, i.e. "create service default/foo"
+	Err  error  // Underlying error
+}
+
+// Error will provide the error message
+//
+// Returns:
+//	- string
+func (e *Error) Error() string {
+	msg := e.Op
+	if e.Err != nil {
+		if msg != "" {
+			msg += ": "
+		}
+		return msg + e.Err.Error()
+	}
+	if e.Kind != nil {
+		if msg != "" {
+			msg += ": "
+		}
+		msg += e.Kind.Error()
+	}
+	return msg
+}
+
+// Unwrap will provide the underlying error
+//
+// Returns:
+//	- error or nil
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is will report if target is the kind of the error
+//
+// Args:
+//	- target error
+//
+// Returns:
+//	- bool
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+// New will create an Error with a message
+//
+// Args:
+//	- sentinel error (kind)
+//	- operation
+//	- format and args, like fmt.Sprintf
+//
+// Returns:
+//	- error
+func New(kind error, op string, format string, args ...interface{}) error {
+	return &Error{
+		Kind: kind,
+		Op:   op,
+		Err:  fmt.Errorf(format, args...),
+	}
+}
+
+// Wrap will wrap the error with the operation, the kind is
+// detected from API Server errors (NotFound, AlreadyExists,
+// Conflict, Forbidden, Invalid, Timeout)
+//
+// Args:
+//	- operation
+//	- error
+//
+// Returns:
+//	- error or nil if error is nil
+func Wrap(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{
+		Kind: KindOf(err),
+		Op:   op,
+		Err:  err,
+	}
+}
+
+// KindOf will provide the sentinel error matching err
+//
+// Args:
+//	- error
+//
+// Returns:
+//	- sentinel error or nil when unknown
+func KindOf(err error) error {
+	var e *Error
+	if errors.As(err, &e) && e.Kind != nil {
+		return e.Kind
+	}
+
+	for _, kind := range []error{
+		ErrNotFound,
+		ErrAlreadyExists,
+		ErrTimeout,
+		ErrInvalidSpec,
+		ErrUnsupportedKind,
+		ErrConflict,
+		ErrForbidden,
+		ErrNotConnected,
+		ErrNotReady,
+	} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		return ErrNotFound
+	case apierrors.IsAlreadyExists(err):
+		return ErrAlreadyExists
+	case apierrors.IsConflict(err):
+		return ErrConflict
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return ErrForbidden
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return ErrInvalidSpec
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return ErrTimeout
+	}
+	return nil
+}
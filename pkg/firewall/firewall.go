@@ -17,10 +17,11 @@ limitations under the License.
 */
 
 import (
-	"errors"
+	"context"
 	"os"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	"github.com/thekubeworld/k8devel/pkg/pod"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
@@ -28,6 +29,7 @@ import (
 // Save will save the current state of firewall
 //
 // Args:
+//	context for cancellation and deadline
 //	client struct
 //	firewallMode - iptables or ipvs
 //	container name
@@ -37,7 +39,8 @@ import (
 //	file object
 //	filesystem which triggered this method
 //
-func Save(c *client.Client,
+func Save(ctx context.Context,
+	c *client.Client,
 	firewallMode string,
 	container string,
 	namespace string) (*os.File, error) {
@@ -50,7 +53,10 @@ func Save(c *client.Client,
 		cmdSave = append(cmdSave, "--save")
 		cmdSave = append(cmdSave, "-n")
 	} else {
-		return nil, errors.New("unknown firewall mode")
+		return nil, k8errors.New(k8errors.ErrInvalidSpec,
+			"save firewall",
+			"unknown firewall mode %q",
+			firewallMode)
 	}
 
 	fileRef, err := util.CreateTempFile(os.TempDir(), "firewall")
@@ -58,7 +64,7 @@ func Save(c *client.Client,
 		return nil, err
 	}
 
-	stdout, _, err := pod.ExecCmd(c,
+	stdout, _, err := pod.ExecCmd(ctx, c,
 		container,
 		namespace,
 		cmdSave)
@@ -45,12 +45,13 @@ type Instance struct {
 // Create will create a job
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- Instance from this module
 //
 // Returns:
 //	- error
-func Create(c *client.Client, i *Instance) error {
+func Create(ctx context.Context, c *client.Client, i *Instance) error {
 
 	restartPolicy, err := util.DetectContainerRestartPolicy(i.RestartPolicy)
 	if err != nil {
@@ -79,9 +80,20 @@ func Create(c *client.Client, i *Instance) error {
 		},
 	}
 
-	_, err = c.Clientset.BatchV1().Jobs(i.Namespace).Create(
-		context.TODO(),
-		jobSpec, metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Job",
+		Namespace: i.Namespace,
+		Name:      jobSpec.Name,
+		Object:    jobSpec,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.BatchV1().Jobs(i.Namespace).Create(
+			ctx,
+			jobSpec,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
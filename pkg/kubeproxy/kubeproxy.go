@@ -18,7 +18,6 @@ limitations under the License.
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
@@ -27,6 +26,7 @@ import (
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/distro/debian/apt"
 	"github.com/thekubeworld/k8devel/pkg/distro/debian/dpkg"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	"github.com/thekubeworld/k8devel/pkg/firewall"
 	"github.com/thekubeworld/k8devel/pkg/pod"
 )
@@ -35,6 +35,7 @@ import (
 // from the kubeproxy pod
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a Client struct
 //	- podname A substring of kube-proxy pod name
 //	- namespace
@@ -42,12 +43,13 @@ import (
 // Returns:
 //	filename storing the firewall rules or error
 //
-func SaveCurrentFirewallState(c *client.Client,
+func SaveCurrentFirewallState(ctx context.Context,
+	c *client.Client,
 	configmapname string,
 	containerName string,
 	namespace string) (string, error) {
 
-	mode, err := DetectKubeProxyMode(c,
+	mode, err := DetectKubeProxyMode(ctx, c,
 		configmapname,
 		containerName,
 		namespace)
@@ -55,17 +57,18 @@ func SaveCurrentFirewallState(c *client.Client,
 		return "", err
 	}
 
-	containerName, err = FindKubeProxyPod(c, containerName, namespace)
+	containerName, err = FindKubeProxyPod(ctx, c, containerName, namespace)
 	if err != nil {
 		return "", err
 	}
 
 	if mode == "ipvs" {
 		// check if ipvsadm exists, if not install it
-		_, err := dpkg.CheckPackageInstalled(c, containerName, namespace, "ipvsadm")
+		_, err := dpkg.CheckPackageInstalled(ctx, c, containerName, namespace, "ipvsadm")
 		if err != nil {
 			// apt update
 			_, err = apt.UpdateInsidePod(
+				ctx,
 				c,
 				containerName,
 				namespace)
@@ -75,6 +78,7 @@ func SaveCurrentFirewallState(c *client.Client,
 
 			// apt install ipvsadm
 			_, err = apt.InstallPackageInsidePod(
+				ctx,
 				c,
 				containerName,
 				namespace,
@@ -84,7 +88,7 @@ func SaveCurrentFirewallState(c *client.Client,
 			}
 		}
 	}
-	filesaved, err := firewall.Save(c,
+	filesaved, err := firewall.Save(ctx, c,
 		mode,
 		containerName,
 		namespace)
@@ -100,6 +104,7 @@ func SaveCurrentFirewallState(c *client.Client,
 // and execute commands or other actions
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a Client struct
 //	- containerName A substring of kube-proxy pod name
 //	- namespace
@@ -108,15 +113,22 @@ func SaveCurrentFirewallState(c *client.Client,
 //     the first kube-proxy pod found from the daemonsets
 //	or error
 //
-func FindKubeProxyPod(c *client.Client,
+func FindKubeProxyPod(ctx context.Context,
+	c *client.Client,
 	containerName string,
 	namespace string) (string, error) {
 	// Validation
-	kyPods, kyNumberPods := pod.FindPodsWithNameContains(c,
+	kyPods, kyNumberPods, err := pod.FindPodsWithNameContains(ctx, c,
 		containerName, namespace)
-	if kyNumberPods < 0 {
-		return "", errors.New(
-			"exiting... unable to find kube-proxy pod")
+	if err != nil {
+		return "", err
+	}
+	if kyNumberPods == 0 {
+		return "", k8errors.New(k8errors.ErrNotFound,
+			"find kube-proxy pod",
+			"no pod with name containing %q in namespace %s",
+			containerName,
+			namespace)
 	}
 	return kyPods[0], nil
 }
@@ -124,6 +136,7 @@ func FindKubeProxyPod(c *client.Client,
 // DetectKubeProxyMode will detect kube-proxy mode
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a Client struct
 //	- configmapname
 //	- containerName
@@ -132,24 +145,25 @@ func FindKubeProxyPod(c *client.Client,
 // Returns:
 //     string (ipvs or iptables) OR error type
 //
-func DetectKubeProxyMode(c *client.Client,
+func DetectKubeProxyMode(ctx context.Context,
+	c *client.Client,
 	configmapname string,
 	containerName string,
 	namespace string) (string, error) {
 
 	// make sure we find at least one kube-proxy pod
-	_, err := FindKubeProxyPod(c, containerName, namespace)
+	_, err := FindKubeProxyPod(ctx, c, containerName, namespace)
 	if err != nil {
 		return "", err
 	}
 
 	// Get configmapname from kube-proxy
 	kproxyConfig, err := c.Clientset.CoreV1().ConfigMaps(namespace).Get(
-		context.TODO(),
+		ctx,
 		configmapname,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get configmap "+namespace+"/"+configmapname, err)
 	}
 
 	// Detect if it's iptables
@@ -166,5 +180,9 @@ func DetectKubeProxyMode(c *client.Client,
 		return "ipvs", nil
 	}
 
-	return "", errors.New("unable to detect the kube-proxy mode")
+	return "", k8errors.New(k8errors.ErrNotFound,
+		"detect kube-proxy mode",
+		"no mode iptables or ipvs in configmap %s/%s",
+		namespace,
+		configmapname)
 }
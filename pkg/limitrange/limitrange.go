@@ -23,6 +23,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
 
@@ -43,6 +44,7 @@ type Instance struct {
 // Delete will delete a LimitRange
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a Client struct
 //	- namespace name
 //	- limitrange name
@@ -50,11 +52,19 @@ type Instance struct {
 // Returns:
 //     error or nil
 //
-func Delete(c *client.Client, namespace string, limitrange string) error {
-	err := c.Clientset.CoreV1().LimitRanges(namespace).Delete(
-		context.TODO(),
-		limitrange,
-		metav1.DeleteOptions{})
+func Delete(ctx context.Context, c *client.Client, namespace string, limitrange string) error {
+	operation := client.Operation{
+		Verb:      "delete",
+		Kind:      "LimitRange",
+		Namespace: namespace,
+		Name:      limitrange,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.CoreV1().LimitRanges(namespace).Delete(
+			ctx,
+			limitrange,
+			c.DeleteOptions())
+	})
 	if err != nil {
 		return err
 	}
@@ -65,14 +75,15 @@ func Delete(c *client.Client, namespace string, limitrange string) error {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.NamespaceList or error
-func List(c *client.Client, namespace string) (*v1.LimitRangeList, error) {
-	limitRanges, err := c.Clientset.CoreV1().LimitRanges(namespace).List(context.TODO(), metav1.ListOptions{})
+func List(ctx context.Context, c *client.Client, namespace string) (*v1.LimitRangeList, error) {
+	limitRanges, err := c.Clientset.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("list limitranges in "+namespace, err)
 	}
 	return limitRanges, nil
 }
@@ -80,12 +91,13 @@ func List(c *client.Client, namespace string) (*v1.LimitRangeList, error) {
 // Create will create a LimitRange
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //
 // Returns:
 //     error or nil
 //
-func Create(c *client.Client, l *Instance) error {
+func Create(ctx context.Context, c *client.Client, l *Instance) error {
 	limitType, err := util.DetectLimitType(l.LimitType)
 	if err != nil {
 		return err
@@ -112,10 +124,20 @@ func Create(c *client.Client, l *Instance) error {
 			},
 		},
 	}
-	lrange, err = c.Clientset.CoreV1().LimitRanges(l.Namespace).Create(
-		context.TODO(),
-		lrange,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "LimitRange",
+		Namespace: l.Namespace,
+		Name:      lrange.Name,
+		Object:    lrange,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().LimitRanges(l.Namespace).Create(
+			ctx,
+			lrange,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -126,20 +148,21 @@ func Create(c *client.Client, l *Instance) error {
 // Exists will check if the namespace exists or not
 //
 // Args:
-//     - Pointer to a Client struct
+//	- context for cancellation and deadline
+//	- Pointer to a Client struct
 //	- namespace name
 //	- limitrange name
 //
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, namespace string, limitrange string) (string, error) {
+func Exists(ctx context.Context, c *client.Client, namespace string, limitrange string) (string, error) {
 	exists, err := c.Clientset.CoreV1().LimitRanges(namespace).Get(
-		context.TODO(),
+		ctx,
 		limitrange,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get limitrange "+namespace+"/"+limitrange, err)
 	}
 
 	return exists.Name, nil
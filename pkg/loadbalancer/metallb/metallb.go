@@ -17,13 +17,14 @@ limitations under the License.
 */
 
 import (
-	"errors"
+	"context"
 	"io/ioutil"
 	"os"
 
 	"github.com/thekubeworld/k8devel/pkg/apply"
 	"github.com/thekubeworld/k8devel/pkg/client"
 	"github.com/thekubeworld/k8devel/pkg/configmap"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	"github.com/thekubeworld/k8devel/pkg/secret"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
@@ -43,21 +44,24 @@ type InstanceConfig struct {
 //
 // Args:
 //
-//      - Client struct from client module
+//	- context for cancellation and deadline
+//	- Client struct from client module
 //	- version to deploy
 //
 // Returns:
-//      - pointer v1.ConfigMapList or error
-func Deploy(c *client.Client, version string) error {
+//	- pointer v1.ConfigMapList or error
+func Deploy(ctx context.Context, c *client.Client, version string) error {
 	if len(version) == 0 {
-		return errors.New("version must be specified")
+		return k8errors.New(k8errors.ErrInvalidSpec,
+			"deploy metallb",
+			"version must be specified")
 	}
 
 	baseURL := "https://raw.githubusercontent.com/metallb/metallb/" + version
 
 	// Namespace
 	url := baseURL + "/manifests/namespace.yaml"
-	fileNamespace, err := util.DownloadFile(url)
+	fileNamespace, err := util.DownloadFileWithClient(ctx, c.HTTPClient, url)
 	if err != nil {
 		return err
 	}
@@ -65,11 +69,11 @@ func Deploy(c *client.Client, version string) error {
 	if err != nil {
 		return err
 	}
-	apply.YAML(c, yamlFile)
+	apply.YAML(ctx, c, yamlFile)
 
 	// Metallb
 	url = baseURL + "/manifests/metallb.yaml"
-	fileMetallb, err := util.DownloadFile(url)
+	fileMetallb, err := util.DownloadFileWithClient(ctx, c.HTTPClient, url)
 	if err != nil {
 		return err
 	}
@@ -77,7 +81,7 @@ func Deploy(c *client.Client, version string) error {
 	if err != nil {
 		return err
 	}
-	apply.YAML(c, yamlFile)
+	apply.YAML(ctx, c, yamlFile)
 
 	// Done, removing files
 	os.Remove(fileNamespace)
@@ -91,12 +95,13 @@ func Deploy(c *client.Client, version string) error {
 //
 // Args:
 //
-//      - Client struct from client module
+//	- context for cancellation and deadline
+//	- Client struct from client module
 //	- InstanceSecret struct
 //
 // Returns:
-//      - nil or error
-func CreateSecret(c *client.Client, s *secret.Instance) error {
+//	- nil or error
+func CreateSecret(ctx context.Context, c *client.Client, s *secret.Instance) error {
 	// Adding secret for metallb
 	s = &secret.Instance{
 		Name:      s.Name,
@@ -106,7 +111,7 @@ func CreateSecret(c *client.Client, s *secret.Instance) error {
 		Value:     s.Value,
 	}
 
-	err := secret.Create(c, s)
+	err := secret.Create(ctx, c, s)
 	if err != nil {
 		return err
 	}
@@ -118,12 +123,13 @@ func CreateSecret(c *client.Client, s *secret.Instance) error {
 //
 // Args:
 //
-//      - Client struct from client module
+//	- context for cancellation and deadline
+//	- Client struct from client module
 //	- InstanceConfig
 //
 // Returns:
-//      - nil or error
-func CreateConfig(c *client.Client, conf *InstanceConfig) error {
+//	- nil or error
+func CreateConfig(ctx context.Context, c *client.Client, conf *InstanceConfig) error {
 	cfgmap := configmap.Instance{
 		Name:      conf.Name,
 		Namespace: conf.Namespace,
@@ -133,7 +139,7 @@ func CreateConfig(c *client.Client, conf *InstanceConfig) error {
 			"\n  addresses:\n  - " + conf.AddressPoolAddresses + "\n",
 	}
 
-	err := configmap.Create(c, &cfgmap)
+	err := configmap.Create(ctx, c, &cfgmap)
 	if err != nil {
 		return err
 	}
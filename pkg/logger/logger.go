@@ -0,0 +1,231 @@
+package logger
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2/klogr"
+)
+
+// Level is the severity of a log message
+type Level int
+
+const (
+	// LevelDebug for messages useful only when troubleshooting
+	LevelDebug Level = iota
+	// LevelInfo for progress of operations (Creating, Deleted, etc)
+	LevelInfo
+	// LevelWarn for unexpected situations that don't stop the operation
+	LevelWarn
+	// LevelError for failed operations
+	LevelError
+)
+
+// String will provide the level name
+//
+// Returns:
+//	- string
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLevel will provide the Level from its name
+//
+// Args:
+//	- name: debug, info, warn (or warning) and error
+//
+// Returns:
+//	- Level or error
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// Logger receives all messages from the library modules
+//
+// keysAndValues are pairs of key (string) and value, example:
+//	Log(LevelInfo, "Deleted service", "name", "foo", "namespace", "bar")
+type Logger interface {
+	Log(level Level, msg string, keysAndValues ...interface{})
+}
+
+var (
+	defaultMutex  sync.RWMutex
+	defaultLogger Logger = Nop()
+)
+
+// SetDefault will set the Logger used by modules without a
+// client.Client (i.e. util) and by clients without Logger set
+//
+// Args:
+//	- Logger, nil means silent
+func SetDefault(l Logger) {
+	if l == nil {
+		l = Nop()
+	}
+	defaultMutex.Lock()
+	defaultLogger = l
+	defaultMutex.Unlock()
+}
+
+// Default will provide the Logger set by SetDefault, silent
+// when never set
+//
+// Returns:
+//	- Logger
+func Default() Logger {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultLogger
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(Level, string, ...interface{}) {}
+
+// Nop will provide a Logger that discards all messages
+//
+// Returns:
+//	- Logger
+func Nop() Logger {
+	return nopLogger{}
+}
+
+// writerLogger writes logfmt style lines
+type writerLogger struct {
+	mutex    sync.Mutex
+	out      io.Writer
+	minLevel Level
+}
+
+// NewWriter will provide a Logger writing one line per message,
+// example:
+//	time=2021-10-01T10:00:00Z level=info msg="Deleted service" name=foo
+//
+// Args:
+//	- io.Writer (i.e. os.Stdout)
+//	- minimal level to be written
+//
+// Returns:
+//	- Logger
+func NewWriter(out io.Writer, minLevel Level) Logger {
+	return &writerLogger{out: out, minLevel: minLevel}
+}
+
+func (w *writerLogger) Log(level Level, msg string, keysAndValues ...interface{}) {
+	if level < w.minLevel {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("time=")
+	b.WriteString(time.Now().UTC().Format(time.RFC3339))
+	b.WriteString(" level=")
+	b.WriteString(level.String())
+	b.WriteString(" msg=")
+	b.WriteString(quote(msg))
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		value := "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = fmt.Sprint(keysAndValues[i+1])
+		}
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(quote(value))
+	}
+	b.WriteString("\n")
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	io.WriteString(w.out, b.String())
+}
+
+// quote will quote the value only when required
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// logrLogger adapts logr.Logger
+type logrLogger struct {
+	log logr.Logger
+}
+
+// NewLogr will provide a Logger backed by logr.Logger. LevelDebug is
+// sent as V(1).Info, LevelError as Error, Info and Warn as Info
+// (Warn with the key "level"="warn")
+//
+// Args:
+//	- logr.Logger
+//
+// Returns:
+//	- Logger
+func NewLogr(l logr.Logger) Logger {
+	return &logrLogger{log: l}
+}
+
+func (l *logrLogger) Log(level Level, msg string, keysAndValues ...interface{}) {
+	switch level {
+	case LevelDebug:
+		l.log.V(1).Info(msg, keysAndValues...)
+	case LevelWarn:
+		l.log.Info(msg, append([]interface{}{"level", "warn"}, keysAndValues...)...)
+	case LevelError:
+		l.log.Error(nil, msg, keysAndValues...)
+	default:
+		l.log.Info(msg, keysAndValues...)
+	}
+}
+
+// NewKlog will provide a Logger backed by klog, the verbosity
+// of debug messages is controlled by klog -v flag
+//
+// Returns:
+//	- Logger
+func NewKlog() Logger {
+	return NewLogr(klogr.New())
+}
@@ -0,0 +1,78 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Document is an object from a manifest
+type Document struct {
+	Index  int                        // position in the manifest, from 0
+	Source string                     // file path, empty when not read from a file
+	Line   int                        // line where the object starts, from 1
+	Object *unstructured.Unstructured // nil when Err is set
+	Err    error                      // the document can't be parsed
+}
+
+// Parse will read the objects of a YAML or JSON manifest, see
+// Decoder
+//
+// Args:
+//	- manifest as []byte
+//
+// Returns:
+//	- slice of Document, in the manifest order
+func Parse(data []byte) []Document {
+	// reading from memory can't fail
+	documents, _ := Read(bytes.NewReader(data), "")
+	return documents
+}
+
+// Result will provide the Result of the document with the object
+// identity, failed when the document can't be parsed
+//
+// Returns:
+//	- Result
+func (d Document) Result() Result {
+	r := Result{Index: d.Index, Source: d.Source, Line: d.Line}
+	if d.Object != nil {
+		r.GVK = d.Object.GroupVersionKind()
+		r.Namespace = d.Object.GetNamespace()
+		r.Name = d.Object.GetName()
+	}
+	if d.Err != nil {
+		r.Action = ActionFailed
+		r.Err = d.Err
+	}
+	return r
+}
+
+// location will provide "file:line", "line N" or empty when
+// the line is unknown
+func location(source string, line int) string {
+	if line == 0 {
+		return source
+	}
+	if len(source) == 0 {
+		return "line " + strconv.Itoa(line)
+	}
+	return source + ":" + strconv.Itoa(line)
+}
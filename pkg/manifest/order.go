@@ -0,0 +1,115 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"sort"
+)
+
+// KindOrder is the order kinds are applied, an object only
+// depends on kinds before it. Kinds not listed (i.e. custom
+// resources) are applied last. Delete uses the reverse order.
+var KindOrder = []string{
+	"CustomResourceDefinition",
+	"Namespace",
+	"ResourceQuota",
+	"LimitRange",
+	"PriorityClass",
+	"PodSecurityPolicy",
+	"StorageClass",
+	"ServiceAccount",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"ConfigMap",
+	"Secret",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"Service",
+	"Endpoints",
+	"NetworkPolicy",
+	"PodDisruptionBudget",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"HorizontalPodAutoscaler",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+// Sort will sort the documents in the apply order (see KindOrder),
+// documents of the same kind keep the manifest order. Documents
+// that can't be parsed go first.
+//
+// Args:
+//	- slice of Document, sorted in place
+func Sort(docs []Document) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		return kindPriority(docs[i]) < kindPriority(docs[j])
+	})
+}
+
+// SortForDelete will sort the documents in the reverse of the
+// apply order, documents of the same kind are in the reverse of
+// the manifest order
+//
+// Args:
+//	- slice of Document, sorted in place
+func SortForDelete(docs []Document) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		pi, pj := kindPriority(docs[i]), kindPriority(docs[j])
+		if pi != pj {
+			return pi > pj
+		}
+		return docs[i].Index > docs[j].Index
+	})
+}
+
+// ByIndex will sort the results in the manifest order
+//
+// Args:
+//	- Results, sorted in place
+func (results Results) ByIndex() {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+}
+
+// kindPriority will provide the position of the document kind in
+// KindOrder, len(KindOrder) for kinds not listed and -1 for
+// documents without object
+func kindPriority(doc Document) int {
+	if doc.Object == nil {
+		return -1
+	}
+	kind := doc.Object.GetKind()
+	for i, k := range KindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(KindOrder)
+}
@@ -0,0 +1,462 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// Overlay changes the objects of a manifest before apply, like
+// kustomize, so an upstream manifest can be used without
+// changing the file. It's applied in this order:
+//	- Patches, matching the objects as in the manifest
+//	- Namespace
+//	- Labels and Annotations
+//	- Images
+// The fields can be read from YAML, see ReadOverlay().
+// Example:
+//	o := manifest.Overlay{
+//		Namespace: "lb",
+//		Images:    []manifest.Image{{Name: "metallb/speaker", NewTag: "v0.10.3"}},
+//	}
+//	docs, err = o.Apply(docs)
+type Overlay struct {
+	// Namespace replaces the namespace of the namespaced objects
+	// (custom resources included), the Namespace object is renamed
+	// and the references to the namespaces replaced (ServiceAccount
+	// subjects of bindings, services of webhooks and APIServices).
+	// Several Namespace objects are an error, they would be the
+	// same one.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Labels are added to the objects and their pod templates,
+	// selectors are not changed
+	Labels map[string]string `json:"commonLabels,omitempty"`
+
+	// Annotations are added to the objects and their pod templates
+	Annotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// Images replaces the images of the containers
+	Images []Image `json:"images,omitempty"`
+
+	// Patches are strategic merge patches or JSON6902 patches
+	Patches []Patch `json:"patches,omitempty"`
+}
+
+// Image replaces the image of the containers with Name
+type Image struct {
+	Name    string `json:"name"`              // image without tag and digest, i.e. nginx
+	NewName string `json:"newName,omitempty"` // replaces the name
+	NewTag  string `json:"newTag,omitempty"`  // replaces the tag and digest
+	Digest  string `json:"digest,omitempty"`  // replaces the tag and digest, i.e. sha256:...
+}
+
+// Patch changes the objects of Target. A strategic merge patch is
+// an object (YAML or JSON), custom resources are merged as JSON
+// merge patch. A JSON6902 patch is a list of operations:
+//	- op: replace
+//	  path: /spec/replicas
+//	  value: 3
+type Patch struct {
+	Patch string `json:"patch,omitempty"`
+	// Path of a file with the patch, relative to the overlay
+	// file with ReadOverlay()
+	Path string `json:"path,omitempty"`
+	// Target selects the objects patched, required by JSON6902
+	// patches. A strategic merge patch without Target patches the
+	// object with its kind, name and namespace.
+	Target *Target `json:"target,omitempty"`
+}
+
+// Target selects objects, empty fields match any value
+type Target struct {
+	Group         string `json:"group,omitempty"`
+	Version       string `json:"version,omitempty"`
+	Kind          string `json:"kind,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"name,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"` // i.e. app=web,tier!=db
+}
+
+// ReadOverlay will read an Overlay from a YAML or JSON file, the
+// Path of the patches is read relative to the file
+//
+// Args:
+//	- path of the overlay file
+//
+// Returns:
+//	- Overlay or error
+func ReadOverlay(path string) (Overlay, error) {
+	var o Overlay
+	op := "read overlay " + path
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return o, k8errors.Wrap(op, err)
+	}
+	if err := yaml.UnmarshalStrict(data, &o); err != nil {
+		return o, k8errors.New(k8errors.ErrInvalidSpec, op, "%s", err)
+	}
+	for i, p := range o.Patches {
+		if len(p.Path) == 0 {
+			continue
+		}
+		file := p.Path
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), file)
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return o, k8errors.Wrap(op, err)
+		}
+		o.Patches[i].Patch = string(data)
+	}
+	return o, nil
+}
+
+// Apply will change the objects of the documents, documents that
+// can't be parsed are kept as is
+//
+// Args:
+//	- slice of Document
+//
+// Returns:
+//	- slice of Document with copies of the objects changed, or
+//	  error when a patch is not valid or doesn't match any object
+func (o Overlay) Apply(docs []Document) ([]Document, error) {
+	out := make([]Document, len(docs))
+	for i, doc := range docs {
+		out[i] = doc
+		if doc.Object != nil {
+			out[i].Object = doc.Object.DeepCopy()
+		}
+	}
+
+	for i, p := range o.Patches {
+		if err := p.apply(out); err != nil {
+			return nil, k8errors.New(k8errors.ErrInvalidSpec,
+				fmt.Sprintf("overlay patch %d", i+1), "%s", err)
+		}
+	}
+	if len(o.Namespace) > 0 {
+		if err := setNamespace(out, o.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	for _, doc := range out {
+		if doc.Object == nil {
+			continue
+		}
+		obj := doc.Object.Object
+		for _, meta := range metadataPaths(obj) {
+			addStrings(obj, o.Labels, append(meta, "labels")...)
+			addStrings(obj, o.Annotations, append(meta, "annotations")...)
+		}
+		for _, image := range o.Images {
+			image.apply(obj)
+		}
+	}
+	return out, nil
+}
+
+// apply will patch the documents matched by the patch target
+func (p Patch) apply(docs []Document) error {
+	data, err := yaml.YAMLToJSON([]byte(p.Patch))
+	if err != nil {
+		return err
+	}
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) == 0 || string(data) == "null" {
+		return fmt.Errorf("patch is empty")
+	}
+
+	var ops jsonpatch.Patch
+	target := p.Target
+	if data[0] == '[' {
+		ops, err = jsonpatch.DecodePatch(data)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return fmt.Errorf("JSON6902 patch requires a target")
+		}
+	} else if target == nil {
+		patch := &unstructured.Unstructured{}
+		if err := patch.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		gvk := patch.GroupVersionKind()
+		target = &Target{
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Namespace: patch.GetNamespace(),
+			Name:      patch.GetName(),
+		}
+	}
+
+	selector, err := labels.Parse(target.LabelSelector)
+	if err != nil {
+		return err
+	}
+
+	matched := false
+	for _, doc := range docs {
+		if doc.Object == nil || !target.matches(doc.Object, selector) {
+			continue
+		}
+		matched = true
+
+		original, err := doc.Object.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		var patched []byte
+		switch {
+		case ops != nil:
+			patched, err = ops.Apply(original)
+		default:
+			patched, err = mergePatch(doc.Object, original, data)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s: %s", doc.Object.GetKind(), doc.Object.GetName(), err)
+		}
+		if err := doc.Object.UnmarshalJSON(patched); err != nil {
+			return err
+		}
+	}
+	if !matched {
+		return fmt.Errorf("no object matches the target")
+	}
+	return nil
+}
+
+// mergePatch will apply a strategic merge patch, a JSON merge
+// patch for kinds unknown to client-go
+func mergePatch(obj *unstructured.Unstructured, original []byte, patch []byte) ([]byte, error) {
+	typed, err := scheme.Scheme.New(obj.GroupVersionKind())
+	if err != nil {
+		return jsonpatch.MergePatch(original, patch)
+	}
+	return strategicpatch.StrategicMergePatch(original, patch, typed)
+}
+
+// matches will report if the object is selected by the target
+func (t Target) matches(obj *unstructured.Unstructured, selector labels.Selector) bool {
+	gvk := obj.GroupVersionKind()
+	switch {
+	case len(t.Group) > 0 && t.Group != gvk.Group:
+		return false
+	case len(t.Version) > 0 && t.Version != gvk.Version:
+		return false
+	case len(t.Kind) > 0 && t.Kind != gvk.Kind:
+		return false
+	case len(t.Namespace) > 0 && t.Namespace != obj.GetNamespace():
+		return false
+	case len(t.Name) > 0 && t.Name != obj.GetName():
+		return false
+	}
+	return selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// setNamespace will move the namespaced objects to the namespace
+// and replace the references to the namespaces they were in, an
+// error when there are several Namespace objects
+func setNamespace(docs []Document, namespace string) error {
+	var namespaces []string
+	for _, doc := range docs {
+		if doc.Object != nil && doc.Object.GetKind() == "Namespace" {
+			namespaces = append(namespaces, doc.Object.GetName())
+		}
+	}
+	if len(namespaces) > 1 {
+		return k8errors.New(k8errors.ErrInvalidSpec, "overlay namespace "+namespace,
+			"%d Namespace objects would be renamed to the same one: %s",
+			len(namespaces), strings.Join(namespaces, ", "))
+	}
+
+	moved := map[string]bool{}
+	for _, doc := range docs {
+		if doc.Object == nil {
+			continue
+		}
+		obj := doc.Object
+		switch {
+		case obj.GetKind() == "Namespace":
+			moved[obj.GetName()] = true
+			obj.SetName(namespace)
+		case !client.ClusterScoped(obj.GetKind()):
+			if len(obj.GetNamespace()) > 0 {
+				moved[obj.GetNamespace()] = true
+			}
+			obj.SetNamespace(namespace)
+		}
+	}
+
+	replace := func(obj map[string]interface{}, fields ...string) {
+		ns, found, _ := unstructured.NestedString(obj, fields...)
+		if found && moved[ns] {
+			_ = unstructured.SetNestedField(obj, namespace, fields...)
+		}
+	}
+	for _, doc := range docs {
+		if doc.Object == nil {
+			continue
+		}
+		obj := doc.Object.Object
+		switch doc.Object.GetKind() {
+		case "RoleBinding", "ClusterRoleBinding":
+			subjects, _, _ := unstructured.NestedSlice(obj, "subjects")
+			for _, s := range subjects {
+				subject, ok := s.(map[string]interface{})
+				if ok && subject["kind"] == "ServiceAccount" {
+					replace(subject, "namespace")
+				}
+			}
+			if subjects != nil {
+				_ = unstructured.SetNestedSlice(obj, subjects, "subjects")
+			}
+		case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration":
+			webhooks, _, _ := unstructured.NestedSlice(obj, "webhooks")
+			for _, w := range webhooks {
+				if webhook, ok := w.(map[string]interface{}); ok {
+					replace(webhook, "clientConfig", "service", "namespace")
+				}
+			}
+			if webhooks != nil {
+				_ = unstructured.SetNestedSlice(obj, webhooks, "webhooks")
+			}
+		case "APIService":
+			replace(obj, "spec", "service", "namespace")
+		}
+	}
+	return nil
+}
+
+// metadataPaths will provide the metadata of the object and of
+// its pod templates
+func metadataPaths(obj map[string]interface{}) [][]string {
+	paths := [][]string{{"metadata"}}
+	for _, spec := range podSpecPaths(obj) {
+		if len(spec) > 1 {
+			// spec of a pod template, not of a Pod
+			meta := append(append([]string{}, spec[:len(spec)-1]...), "metadata")
+			paths = append(paths, meta)
+		}
+	}
+	return paths
+}
+
+// podSpecPaths will provide the pod specs of the object: Pods,
+// workloads with a pod template and CronJobs
+func podSpecPaths(obj map[string]interface{}) [][]string {
+	var paths [][]string
+	for _, path := range [][]string{
+		{"spec", "template", "spec"},
+		{"spec", "jobTemplate", "spec", "template", "spec"},
+	} {
+		if _, found, _ := unstructured.NestedMap(obj, path...); found {
+			paths = append(paths, path)
+		}
+	}
+	if obj["kind"] == "Pod" {
+		paths = append(paths, []string{"spec"})
+	}
+	return paths
+}
+
+// addStrings will add the values to the string map of the fields
+func addStrings(obj map[string]interface{}, values map[string]string, fields ...string) {
+	if len(values) == 0 {
+		return
+	}
+	m, _, _ := unstructured.NestedStringMap(obj, fields...)
+	if m == nil {
+		m = map[string]string{}
+	}
+	for key, value := range values {
+		m[key] = value
+	}
+	_ = unstructured.SetNestedStringMap(obj, m, fields...)
+}
+
+// apply will replace the image of the containers matching Name
+func (i Image) apply(obj map[string]interface{}) {
+	for _, spec := range podSpecPaths(obj) {
+		for _, field := range []string{"initContainers", "containers"} {
+			path := append(append([]string{}, spec...), field)
+			containers, found, _ := unstructured.NestedSlice(obj, path...)
+			if !found {
+				continue
+			}
+			for _, c := range containers {
+				container, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				image, ok := container["image"].(string)
+				if !ok {
+					continue
+				}
+				if newImage, ok := i.replace(image); ok {
+					container["image"] = newImage
+				}
+			}
+			_ = unstructured.SetNestedSlice(obj, containers, path...)
+		}
+	}
+}
+
+// replace will provide the new image when the image matches
+// Name, i.e. nginx:1.20 or registry:5000/nginx@sha256:...
+func (i Image) replace(image string) (string, bool) {
+	name, tag := image, ""
+	if at := strings.Index(name, "@"); at >= 0 {
+		name, tag = name[:at], name[at:]
+	}
+	// a colon after the last slash is the tag, not the port
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name, tag = name[:colon], name[colon:]+tag
+	}
+	if name != i.Name {
+		return "", false
+	}
+
+	if len(i.NewName) > 0 {
+		name = i.NewName
+	}
+	switch {
+	case len(i.Digest) > 0:
+		tag = "@" + i.Digest
+	case len(i.NewTag) > 0:
+		tag = ":" + i.NewTag
+	}
+	return name + tag, true
+}
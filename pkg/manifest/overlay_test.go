@@ -0,0 +1,336 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+func TestImageReplace(t *testing.T) {
+	digest := "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+
+	tests := []struct {
+		name      string
+		image     Image
+		container string
+		want      string
+		wantMatch bool
+	}{
+		{
+			name:      "new tag",
+			image:     Image{Name: "nginx", NewTag: "1.21"},
+			container: "nginx:1.20",
+			want:      "nginx:1.21",
+			wantMatch: true,
+		},
+		{
+			name:      "without tag",
+			image:     Image{Name: "nginx", NewTag: "1.21"},
+			container: "nginx",
+			want:      "nginx:1.21",
+			wantMatch: true,
+		},
+		{
+			name:      "new name keeps the tag",
+			image:     Image{Name: "nginx", NewName: "mirror.local/nginx"},
+			container: "nginx:1.20",
+			want:      "mirror.local/nginx:1.20",
+			wantMatch: true,
+		},
+		{
+			name:      "registry port is not a tag",
+			image:     Image{Name: "registry:5000/metallb/speaker", NewTag: "v0.10.3"},
+			container: "registry:5000/metallb/speaker:v0.10.2",
+			want:      "registry:5000/metallb/speaker:v0.10.3",
+			wantMatch: true,
+		},
+		{
+			name:      "registry port without tag",
+			image:     Image{Name: "registry:5000/metallb/speaker", NewTag: "v0.10.3"},
+			container: "registry:5000/metallb/speaker",
+			want:      "registry:5000/metallb/speaker:v0.10.3",
+			wantMatch: true,
+		},
+		{
+			name:      "registry port only in the name",
+			image:     Image{Name: "registry", NewTag: "2"},
+			container: "registry:5000/metallb/speaker",
+		},
+		{
+			name:      "digest replaces the tag",
+			image:     Image{Name: "nginx", Digest: digest},
+			container: "nginx:1.20",
+			want:      "nginx@" + digest,
+			wantMatch: true,
+		},
+		{
+			name:      "tag replaces the digest",
+			image:     Image{Name: "registry:5000/nginx", NewTag: "1.21"},
+			container: "registry:5000/nginx@" + digest,
+			want:      "registry:5000/nginx:1.21",
+			wantMatch: true,
+		},
+		{
+			name:      "tag and digest",
+			image:     Image{Name: "nginx", NewName: "mirror.local/nginx"},
+			container: "nginx:1.20@" + digest,
+			want:      "mirror.local/nginx:1.20@" + digest,
+			wantMatch: true,
+		},
+		{
+			name:      "other image",
+			image:     Image{Name: "nginx", NewTag: "1.21"},
+			container: "library/nginx:1.20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, match := tt.image.replace(tt.container)
+			if match != tt.wantMatch || got != tt.want {
+				t.Errorf("replace(%q) = %q, %v, want %q, %v",
+					tt.container, got, match, tt.want, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestOverlayApply(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: upstream
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: speaker
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: speaker
+subjects:
+- kind: ServiceAccount
+  name: speaker
+  namespace: upstream
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: upstream
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.20
+      - name: sidecar
+        image: busybox
+`
+
+	tests := []struct {
+		name     string
+		overlay  Overlay
+		manifest string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "namespace",
+			overlay:  Overlay{Namespace: "lb"},
+			manifest: manifest,
+			want: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: lb
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: speaker
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: speaker
+subjects:
+- kind: ServiceAccount
+  name: speaker
+  namespace: lb
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: lb
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.20
+      - name: sidecar
+        image: busybox
+`,
+		},
+		{
+			name: "labels, images and patches",
+			overlay: Overlay{
+				Labels: map[string]string{"team": "net"},
+				Images: []Image{{Name: "nginx", NewTag: "1.21"}},
+				Patches: []Patch{
+					{Patch: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          limits:
+            cpu: 100m
+`},
+					{
+						Patch:  "- op: replace\n  path: /spec/replicas\n  value: 3\n",
+						Target: &Target{Kind: "Deployment", LabelSelector: "team!=db"},
+					},
+				},
+			},
+			manifest: manifest,
+			want: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: upstream
+  labels:
+    team: net
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: speaker
+  labels:
+    team: net
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: speaker
+subjects:
+- kind: ServiceAccount
+  name: speaker
+  namespace: upstream
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: upstream
+  labels:
+    team: net
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+        team: net
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.21
+        resources:
+          limits:
+            cpu: 100m
+      - name: sidecar
+        image: busybox
+`,
+		},
+		{
+			name: "patch without match",
+			overlay: Overlay{Patches: []Patch{{
+				Patch:  "- op: remove\n  path: /spec/replicas\n",
+				Target: &Target{Kind: "StatefulSet"},
+			}}},
+			manifest: manifest,
+			wantErr:  k8errors.ErrInvalidSpec,
+		},
+		{
+			name:    "several namespaces",
+			overlay: Overlay{Namespace: "lb"},
+			manifest: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: one
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: two
+`,
+			wantErr: k8errors.ErrInvalidSpec,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := Parse([]byte(tt.manifest))
+			got, err := tt.overlay.Apply(docs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			want := Parse([]byte(tt.want))
+			if len(got) != len(want) {
+				t.Fatalf("Apply() = %d documents, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !reflect.DeepEqual(got[i].Object.Object, want[i].Object.Object) {
+					t.Errorf("document %d:\n got %v\nwant %v", i, got[i].Object.Object, want[i].Object.Object)
+				}
+			}
+
+			// the documents are copied, not changed
+			if !reflect.DeepEqual(docs, Parse([]byte(tt.manifest))) {
+				t.Error("Apply() changed the documents")
+			}
+		})
+	}
+}
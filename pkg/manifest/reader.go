@@ -0,0 +1,450 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// Extensions are the file extensions read by ReadDir
+var Extensions = []string{".yaml", ".yml", ".json"}
+
+// readerSize is the buffer of the Decoder, leading whitespace
+// longer than it makes the input be read as YAML
+const readerSize = 64 * 1024
+
+var (
+	// documentMarker matches the lines splitting YAML documents:
+	// "---" or "..." with optional trailing comment
+	documentMarker = regexp.MustCompile(`^(---|\.\.\.)(\s+#.*)?\s*$`)
+
+	// yamlLine matches the line reported by YAML errors, relative
+	// to the document
+	yamlLine = regexp.MustCompile(`line (\d+)`)
+
+	utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// Decoder reads the objects of a manifest one at a time, the
+// manifest can be:
+//	- YAML, one or more documents split by "---"
+//	- JSON, one or more objects or an array of objects
+// Lists (i.e. kind: List) are flattened into their items.
+// Example:
+//	d := manifest.NewDecoder(os.Stdin, "stdin")
+//	for {
+//		doc, err := d.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+type Decoder struct {
+	source  string
+	reader  *bufio.Reader
+	started bool
+	eof     bool
+	line    int        // lines read, YAML only
+	index   int        // next Document.Index
+	pending []Document // decoded, not returned yet
+
+	json      *json.Decoder
+	jsonLines *lineCounter
+}
+
+// NewDecoder will create a Decoder
+//
+// Args:
+//	- io.Reader
+//	- source for messages, i.e. the file path, can be empty
+//
+// Returns:
+//	- pointer to Decoder
+func NewDecoder(r io.Reader, source string) *Decoder {
+	return &Decoder{
+		source: source,
+		reader: bufio.NewReaderSize(r, readerSize),
+	}
+}
+
+// Decode will provide the next object of the manifest. Documents
+// that can't be parsed are returned with Document.Err set, the
+// error returned is only for failures reading the input.
+//
+// Returns:
+//	- Document or error (io.EOF at the end of the manifest)
+func (d *Decoder) Decode() (Document, error) {
+	for len(d.pending) == 0 {
+		if d.eof {
+			return Document{}, io.EOF
+		}
+		if !d.started {
+			d.started = true
+			if err := d.detect(); err != nil {
+				return Document{}, err
+			}
+		}
+
+		var err error
+		if d.json != nil {
+			err = d.decodeJSON()
+		} else {
+			err = d.decodeYAML()
+		}
+		if err != nil {
+			return Document{}, k8errors.Wrap("read manifest "+d.source, err)
+		}
+	}
+
+	doc := d.pending[0]
+	d.pending = d.pending[1:]
+	return doc, nil
+}
+
+// detect will skip the byte order mark and select JSON when the
+// first character is '{' or '['
+func (d *Decoder) detect() error {
+	if b, _ := d.reader.Peek(len(utf8BOM)); bytes.Equal(b, utf8BOM) {
+		if _, err := d.reader.Discard(len(utf8BOM)); err != nil {
+			return err
+		}
+	}
+
+	for n := 1; n <= readerSize; n++ {
+		b, _ := d.reader.Peek(n)
+		// end of input or leading whitespace bigger than the buffer
+		if len(b) < n {
+			return nil
+		}
+		switch b[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			d.jsonLines = &lineCounter{reader: d.reader}
+			d.json = json.NewDecoder(d.jsonLines)
+		}
+		return nil
+	}
+	return nil
+}
+
+// decodeYAML will read the next YAML document into pending
+func (d *Decoder) decodeYAML() error {
+	var buf bytes.Buffer
+	start := d.line + 1
+	for {
+		line, err := d.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			d.line++
+			if documentMarker.Match(bytes.TrimRight(line, "\r\n")) {
+				d.addYAML(buf.Bytes(), start)
+				return nil
+			}
+			buf.Write(line)
+		}
+		if err == io.EOF {
+			d.eof = true
+			d.addYAML(buf.Bytes(), start)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// addYAML will parse a YAML document starting at the line,
+// documents empty or with only comments are skipped
+func (d *Decoder) addYAML(data []byte, start int) {
+	first := firstContentLine(data)
+	if first == 0 {
+		return
+	}
+	line := start + first - 1
+
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		d.addError(line, shiftLines(err.Error(), start-1))
+		return
+	}
+	d.addObject(data, line)
+}
+
+// decodeJSON will read the next JSON value into pending
+func (d *Decoder) decodeJSON() error {
+	var raw json.RawMessage
+	if err := d.json.Decode(&raw); err != nil {
+		d.eof = true
+		if err == io.EOF {
+			return nil
+		}
+		// InputOffset is the end of the last value, the error
+		// is at the character reported or the end of the input
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			d.addError(d.jsonLines.lineAt(syntaxErr.Offset-1), err.Error())
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			d.addError(d.jsonLines.lineAt(d.jsonLines.read-1), err.Error())
+			return nil
+		}
+		return err
+	}
+	line := d.jsonLines.lineAt(d.json.InputOffset() - int64(len(raw)))
+
+	if raw[0] != '[' {
+		d.addObject(raw, line)
+		return nil
+	}
+
+	// array of objects, the line of each item is counted from
+	// the array start
+	items := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := items.Token(); err != nil {
+		return err
+	}
+	for items.More() {
+		var item json.RawMessage
+		if err := items.Decode(&item); err != nil {
+			return err
+		}
+		offset := items.InputOffset() - int64(len(item))
+		d.addObject(item, line+bytes.Count(raw[:offset], []byte("\n")))
+	}
+	return nil
+}
+
+// addObject will add the object of the JSON document, lists are
+// flattened into their items
+func (d *Decoder) addObject(data []byte, line int) {
+	var object map[string]interface{}
+	if err := utiljson.Unmarshal(data, &object); err != nil {
+		d.addError(line, "document is not an object: "+err.Error())
+		return
+	}
+	// null document
+	if object == nil {
+		return
+	}
+
+	obj := &unstructured.Unstructured{Object: object}
+	if !strings.HasSuffix(obj.GetKind(), "List") || !obj.IsList() {
+		d.add(obj, line)
+		return
+	}
+
+	list, err := obj.ToList()
+	if err != nil {
+		d.addError(line, err.Error())
+		return
+	}
+	for i := range list.Items {
+		d.add(&list.Items[i], line)
+	}
+}
+
+// add will add the object, apiVersion and kind are required
+func (d *Decoder) add(obj *unstructured.Unstructured, line int) {
+	if len(obj.GetAPIVersion()) == 0 || len(obj.GetKind()) == 0 {
+		d.addError(line, "apiVersion and kind are required")
+		return
+	}
+	d.pending = append(d.pending, Document{
+		Index:  d.index,
+		Source: d.source,
+		Line:   line,
+		Object: obj,
+	})
+	d.index++
+}
+
+// addError will add a document that can't be parsed
+func (d *Decoder) addError(line int, msg string) {
+	d.pending = append(d.pending, Document{
+		Index:  d.index,
+		Source: d.source,
+		Line:   line,
+		Err:    k8errors.New(k8errors.ErrInvalidSpec, "parse manifest", "%s", msg),
+	})
+	d.index++
+}
+
+// Read will read all objects of a manifest
+//
+// Args:
+//	- io.Reader
+//	- source for messages, i.e. the file path, can be empty
+//
+// Returns:
+//	- slice of Document, in the manifest order, or error
+func Read(r io.Reader, source string) ([]Document, error) {
+	var documents []Document
+	d := NewDecoder(r, source)
+	for {
+		doc, err := d.Decode()
+		if err == io.EOF {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, doc)
+	}
+}
+
+// ReadFile will read all objects of a manifest file
+//
+// Args:
+//	- path of the YAML or JSON file
+//
+// Returns:
+//	- slice of Document, in the manifest order, or error
+func ReadFile(path string) ([]Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, k8errors.Wrap("read manifest "+path, err)
+	}
+	defer f.Close()
+
+	return Read(f, path)
+}
+
+// ReadDir will read all manifest files (see Extensions) of the
+// directory and its subdirectories, in lexical order. A path to
+// a file is read as ReadFile().
+//
+// Args:
+//	- path of the directory
+//
+// Returns:
+//	- slice of Document, Index counted across files, or error
+func ReadDir(path string) ([]Document, error) {
+	return readDir(path, ReadFile)
+}
+
+// readDir will read the manifest files of the directory with
+// the read function
+func readDir(path string, read func(path string) ([]Document, error)) ([]Document, error) {
+	var files []string
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// path itself is always read
+		if p == path || isManifest(p) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, k8errors.Wrap("read manifests "+path, err)
+	}
+
+	var documents []Document
+	for _, file := range files {
+		docs, err := read(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			doc.Index = len(documents)
+			documents = append(documents, doc)
+		}
+	}
+	return documents, nil
+}
+
+// isManifest will report if the file has one of the Extensions
+func isManifest(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// firstContentLine will provide the first line, from 1, that is
+// not empty or a comment, 0 when there is none
+func firstContentLine(data []byte) int {
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// shiftLines will make the lines of a YAML error relative to the
+// manifest instead of the document
+func shiftLines(msg string, offset int) string {
+	return yamlLine.ReplaceAllStringFunc(msg, func(s string) string {
+		n, err := strconv.Atoi(strings.TrimPrefix(s, "line "))
+		if err != nil {
+			return s
+		}
+		return fmt.Sprintf("line %d", n+offset)
+	})
+}
+
+// lineCounter keeps the offsets of the new lines read, to find
+// the line of a JSON value
+type lineCounter struct {
+	reader   io.Reader
+	read     int64
+	newlines []int64
+}
+
+// Read will read from the underlying reader
+func (l *lineCounter) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			l.newlines = append(l.newlines, l.read+int64(i))
+		}
+	}
+	l.read += int64(n)
+	return n, err
+}
+
+// lineAt will provide the line, from 1, of the offset
+func (l *lineCounter) lineAt(offset int64) int {
+	return 1 + sort.Search(len(l.newlines), func(i int) bool {
+		return l.newlines[i] >= offset
+	})
+}
@@ -0,0 +1,301 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"errors"
+	"testing"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// parsed is the expected Document: the object kind and name or
+// an error (ErrInvalidSpec), at the line
+type parsed struct {
+	kind string
+	name string
+	line int
+	err  bool
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []parsed
+	}{
+		{
+			name: "yaml documents",
+			manifest: `# leading comment
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+# comment before the object
+
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`,
+			want: []parsed{
+				{kind: "Namespace", name: "demo", line: 2},
+				{kind: "ConfigMap", name: "settings", line: 9},
+			},
+		},
+		{
+			name: "markers with comments and document end",
+			manifest: `--- # first
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+...
+---   # second
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 2},
+				{kind: "ConfigMap", name: "b", line: 8},
+			},
+		},
+		{
+			name: "marker inside a block scalar",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: docs
+data:
+  two.yaml: |
+    a: 1
+    ---
+    b: 2
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "docs", line: 1},
+				{kind: "Secret", name: "token", line: 11},
+			},
+		},
+		{
+			name: "empty and comment only documents are skipped",
+			manifest: `---
+---
+# nothing here
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 5},
+			},
+		},
+		{
+			name: "invalid documents",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+metadata:
+  name: no-kind
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+	name: tab
+---
+- not an object
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 1},
+				{line: 6, err: true},
+				{line: 9, err: true},
+				{line: 14, err: true},
+				{kind: "ConfigMap", name: "b", line: 16},
+			},
+		},
+		{
+			name: "yaml list",
+			manifest: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: b
+`,
+			want: []parsed{
+				{kind: "Namespace", name: "demo", line: 1},
+				{kind: "ConfigMap", name: "a", line: 6},
+				{kind: "Service", name: "b", line: 6},
+			},
+		},
+		{
+			name: "json objects",
+			manifest: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}
+
+{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {"name": "b"}
+}
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 1},
+				{kind: "ConfigMap", name: "b", line: 3},
+			},
+		},
+		{
+			name: "json array",
+			manifest: `
+[
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}},
+  {
+    "apiVersion": "v1",
+    "kind": "ConfigMap",
+    "metadata": {"name": "b"}
+  }
+]
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 3},
+				{kind: "ConfigMap", name: "b", line: 4},
+			},
+		},
+		{
+			name: "json list",
+			manifest: `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}},
+    {"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "b"}}
+  ]
+}
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 1},
+				{kind: "Secret", name: "b", line: 1},
+			},
+		},
+		{
+			name: "json syntax error",
+			manifest: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}
+{"apiVersion": "v1",
+  "kind": }
+`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 1},
+				{line: 3, err: true},
+			},
+		},
+		{
+			name: "json unexpected end",
+			manifest: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "a"}}
+{"apiVersion": "v1",
+  "kind": "ConfigMap"`,
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 1},
+				{line: 3, err: true},
+			},
+		},
+		{
+			name:     "byte order mark",
+			manifest: "\xEF\xBB\xBF{\"apiVersion\": \"v1\", \"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"a\"}}",
+			want: []parsed{
+				{kind: "ConfigMap", name: "a", line: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := Parse([]byte(tt.manifest))
+			if len(docs) != len(tt.want) {
+				t.Fatalf("Parse() = %d documents, want %d: %+v", len(docs), len(tt.want), docs)
+			}
+			for i, doc := range docs {
+				want := tt.want[i]
+				if doc.Index != i {
+					t.Errorf("document %d: Index = %d", i, doc.Index)
+				}
+				if doc.Line != want.line {
+					t.Errorf("document %d: Line = %d, want %d", i, doc.Line, want.line)
+				}
+				if want.err {
+					if !errors.Is(doc.Err, k8errors.ErrInvalidSpec) || doc.Object != nil {
+						t.Errorf("document %d: Err = %v, want ErrInvalidSpec", i, doc.Err)
+					}
+					continue
+				}
+				if doc.Err != nil {
+					t.Errorf("document %d: unexpected error: %v", i, doc.Err)
+					continue
+				}
+				if doc.Object.GetKind() != want.kind || doc.Object.GetName() != want.name {
+					t.Errorf("document %d: %s %s, want %s %s", i,
+						doc.Object.GetKind(), doc.Object.GetName(), want.kind, want.name)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBlockScalar(t *testing.T) {
+	docs := Parse([]byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: docs
+data:
+  two.yaml: |
+    a: 1
+    ---
+    b: 2
+`))
+	if len(docs) != 1 || docs[0].Err != nil {
+		t.Fatalf("Parse() = %+v, want one ConfigMap", docs)
+	}
+	data, _ := docs[0].Object.Object["data"].(map[string]interface{})
+	if want := "a: 1\n---\nb: 2\n"; data["two.yaml"] != want {
+		t.Errorf("two.yaml = %q, want %q", data["two.yaml"], want)
+	}
+}
@@ -0,0 +1,315 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+// Action is what happened to an object
+type Action string
+
+const (
+	// ActionCreated the object didn't exist
+	ActionCreated Action = "created"
+	// ActionConfigured the object existed and was changed
+	ActionConfigured Action = "configured"
+	// ActionUnchanged the object existed and already matched
+	ActionUnchanged Action = "unchanged"
+	// ActionDeleted the object was deleted
+	ActionDeleted Action = "deleted"
+	// ActionPruned the object was deleted as it's not in the
+	// manifest anymore
+	ActionPruned Action = "pruned"
+	// ActionRolledBack the object was created or changed and
+	// then rolled back, see apply Options.Atomic
+	ActionRolledBack Action = "rolled back"
+	// ActionSkipped the object wasn't applied as a document
+	// before failed, see apply Options.Atomic
+	ActionSkipped Action = "skipped"
+	// ActionFailed the operation failed, see Result.Err
+	ActionFailed Action = "failed"
+)
+
+// Result is the outcome of a document from a manifest
+type Result struct {
+	Index     int    // position of the document in the manifest, from 0, -1 when not from the manifest
+	Source    string // file path, empty when not read from a file
+	Line      int    // line where the object starts, from 1
+	GVK       schema.GroupVersionKind
+	Namespace string // empty for cluster scoped objects
+	Name      string
+	Action    Action
+	DryRun    client.DryRunMode
+	Ready     bool   // the object is ready, only set when waited (see apply Options.Wait)
+	Diff      string // unified diff with the live object, only set by apply.Diff
+	Err       error
+}
+
+// Document will provide the document position for messages,
+// example: "document 3 at app.yaml:12 (Deployment default/nginx)"
+//
+// Returns:
+//	- string
+func (r Result) Document() string {
+	s := fmt.Sprintf("document %d", r.Index+1)
+	if r.Index < 0 {
+		s = "inventory"
+	}
+	if loc := location(r.Source, r.Line); len(loc) > 0 {
+		s += " at " + loc
+	}
+	if len(r.GVK.Kind) == 0 && len(r.Name) == 0 {
+		return s
+	}
+	s += " (" + r.GVK.Kind
+	if len(r.Name) > 0 {
+		s += " " + r.Object()
+	}
+	return s + ")"
+}
+
+// Object will provide namespace/name or name of the object, for
+// messages
+//
+// Returns:
+//	- string
+func (r Result) Object() string {
+	if len(r.Namespace) > 0 {
+		return r.Namespace + "/" + r.Name
+	}
+	return r.Name
+}
+
+// String will provide the result as a line, example:
+// "deployment nginx configured (server dry run)", or the
+// error message when it failed
+//
+// Returns:
+//	- string
+func (r Result) String() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	return strings.ToLower(r.GVK.Kind) + " " + r.Name + " " + string(r.Action) + DryRunSuffix(r.DryRun)
+}
+
+// Results are the results of all documents of a manifest
+type Results []Result
+
+// Err will provide the aggregate error of the failed documents
+//
+// Returns:
+//	- *Error or nil when no document failed
+func (results Results) Err() error {
+	var failed []Result
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &Error{Failed: failed, Total: len(results)}
+}
+
+// Count will provide the number of results with the action
+//
+// Args:
+//	- Action
+//
+// Returns:
+//	- int
+func (results Results) Count(action Action) int {
+	n := 0
+	for _, r := range results {
+		if r.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// NotReady will provide the results of the objects that were
+// waited and never became ready, Err has the reason
+//
+// Returns:
+//	- Results
+func (results Results) NotReady() Results {
+	var notReady Results
+	for _, r := range results {
+		if errors.Is(r.Err, k8errors.ErrNotReady) {
+			notReady = append(notReady, r)
+		}
+	}
+	return notReady
+}
+
+// Summary will provide the number of objects per action,
+// example: "2 created, 1 unchanged, 1 failed, 1 not ready"
+//
+// Returns:
+//	- string
+func (results Results) Summary() string {
+	var parts []string
+	for _, action := range []Action{
+		ActionCreated,
+		ActionConfigured,
+		ActionUnchanged,
+		ActionDeleted,
+		ActionPruned,
+		ActionRolledBack,
+		ActionSkipped,
+		ActionFailed,
+	} {
+		if n := results.Count(action); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, action))
+		}
+	}
+	if n := len(results.NotReady()); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d not ready", n))
+	}
+	if len(parts) == 0 {
+		return "no objects"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteTable will write the results as a table followed by the
+// summary, example:
+//	#  KIND        NAMESPACE  NAME   ACTION   ERROR
+//	1  Namespace              demo   created
+//	2  Deployment  demo       nginx  failed   ...
+//
+// Args:
+//	- io.Writer
+//
+// Returns:
+//	- error or nil
+func (results Results) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tKIND\tNAMESPACE\tNAME\tACTION\tERROR")
+	for _, r := range results {
+		errMsg := ""
+		if r.Err != nil {
+			errMsg = r.Err.Error()
+		}
+		index := "-"
+		if r.Index >= 0 {
+			index = strconv.Itoa(r.Index + 1)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			index,
+			r.GVK.Kind,
+			r.Namespace,
+			r.Name,
+			string(r.Action)+DryRunSuffix(r.DryRun),
+			errMsg)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, results.Summary())
+	return err
+}
+
+// WriteDiff will write the diffs of the results, objects
+// unchanged have no diff
+//
+// Args:
+//	- io.Writer
+//
+// Returns:
+//	- error or nil
+func (results Results) WriteDiff(w io.Writer) error {
+	for _, r := range results {
+		if len(r.Diff) == 0 {
+			continue
+		}
+		if _, err := io.WriteString(w, r.Diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Error is the aggregate error of the failed documents
+type Error struct {
+	Failed []Result // results with Err set
+	Total  int      // number of documents
+}
+
+// Error will provide one message per failed document
+//
+// Returns:
+//	- string
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		msgs = append(msgs, r.Document()+": "+r.Err.Error())
+	}
+	return fmt.Sprintf("%d of %d objects failed: %s",
+		len(e.Failed),
+		e.Total,
+		strings.Join(msgs, "; "))
+}
+
+// Is will report if the error of any document matches target,
+// i.e. errors.Is(err, k8errors.ErrAlreadyExists)
+//
+// Args:
+//	- target error
+//
+// Returns:
+//	- bool
+func (e *Error) Is(target error) bool {
+	for _, r := range e.Failed {
+		if errors.Is(r.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// DryRunSuffix will provide the suffix for messages when the
+// client is in dry-run mode
+//
+// Args:
+//	- DryRunMode
+//
+// Returns:
+//	- string
+func DryRunSuffix(mode client.DryRunMode) string {
+	switch mode {
+	case client.DryRunServer:
+		return " (server dry run)"
+	case client.DryRunClient:
+		return " (dry run)"
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/util"
+)
+
+// variable matches ${VAR}, ${VAR:-default} and the escaped form
+// $${VAR}
+var variable = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// TemplateMode selects how a Template renders the manifest
+type TemplateMode int
+
+const (
+	// TemplateBoth executes the Go template and then replaces
+	// the variables, the default
+	TemplateBoth TemplateMode = iota
+	// TemplateGo only executes the Go template, ${VAR} is kept
+	// as is
+	TemplateGo
+	// TemplateVars only replaces the variables, {{ }} is kept as
+	// is, i.e. for manifests with Go templates of their own
+	TemplateVars
+)
+
+// Template renders a manifest before it's decoded, the manifest
+// can use:
+//	- Go text/template, the Values are the data:
+//	  {{ .namespace }}, {{ randomName "test" }}
+//	- variables from the Values: ${namespace} or
+//	  ${namespace:-default}, $${namespace} is kept as ${namespace}
+// The Go template is executed first, a value missing in it is an
+// error. A variable without value nor default is kept as is,
+// i.e. ${HOME} in a script of a ConfigMap, unless Strict. Line
+// numbers of the documents are the ones of the rendered
+// manifest.
+// Example:
+//	t := manifest.Template{Values: map[string]interface{}{"ns": ns}}
+//	docs, err := t.ReadDir("manifests/")
+type Template struct {
+	Values map[string]interface{} // data of the template and variables
+	Funcs  template.FuncMap       // added to TemplateFuncs()
+	Mode   TemplateMode           // TemplateBoth when not set
+	// Strict makes a variable without value nor default an
+	// error
+	Strict bool
+}
+
+// TemplateFuncs will provide the functions of every Template:
+//	- randomString N: N random lowercase letters
+//	- randomName PREFIX: PREFIX-xxxxx, for names of namespaces
+//	  and objects
+//	- base64 S and base64Decode S
+//	- quote S: S as a quoted string
+//	- default D V: V or D when V is empty
+// A random value can be kept in a template variable to be used
+// in several documents: {{ $ns := randomName "test" }}
+//
+// Returns:
+//	- template.FuncMap
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"randomString": func(n int) (string, error) {
+			return util.GenerateRandomString(n, "lower")
+		},
+		"randomName": func(prefix string) (string, error) {
+			s, err := util.GenerateRandomString(5, "lower")
+			return prefix + "-" + s, err
+		},
+		"base64": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"base64Decode": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+		"quote": func(v interface{}) string {
+			return strconv.Quote(fmt.Sprint(v))
+		},
+		"default": func(d interface{}, v interface{}) interface{} {
+			if v == nil || fmt.Sprint(v) == "" {
+				return d
+			}
+			return v
+		},
+	}
+}
+
+// Render will render the manifest
+//
+// Args:
+//	- manifest as []byte
+//	- source for messages, i.e. the file path, can be empty
+//
+// Returns:
+//	- manifest rendered or error
+func (t Template) Render(data []byte, source string) ([]byte, error) {
+	op := "render manifest"
+	if len(source) > 0 {
+		op += " " + source
+	}
+
+	if t.Mode != TemplateVars {
+		name := source
+		if len(name) == 0 {
+			name = "manifest"
+		}
+		funcs := TemplateFuncs()
+		for name, f := range t.Funcs {
+			funcs[name] = f
+		}
+		tmpl, err := template.New(name).
+			Funcs(funcs).
+			Option("missingkey=error").
+			Parse(string(data))
+		if err != nil {
+			return nil, k8errors.New(k8errors.ErrInvalidSpec, op, "%s", err)
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, t.Values); err != nil {
+			return nil, k8errors.New(k8errors.ErrInvalidSpec, op, "%s", err)
+		}
+		data = out.Bytes()
+	}
+	if t.Mode == TemplateGo {
+		return data, nil
+	}
+
+	var missing []string
+	out := variable.ReplaceAllFunc(data, func(match []byte) []byte {
+		m := variable.FindSubmatch(match)
+		if len(m[1]) > 0 {
+			return match[1:]
+		}
+		if value, ok := t.Values[string(m[2])]; ok {
+			return []byte(fmt.Sprint(value))
+		}
+		if bytes.Contains(match, []byte(":-")) {
+			return m[3]
+		}
+		missing = append(missing, string(match))
+		return match
+	})
+	if len(missing) > 0 && t.Strict {
+		return nil, k8errors.New(k8errors.ErrInvalidSpec, op,
+			"no value for %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// Parse will render the manifest and read its objects, see
+// manifest.Parse()
+//
+// Args:
+//	- manifest as []byte
+//
+// Returns:
+//	- slice of Document, in the manifest order, or error
+func (t Template) Parse(data []byte) ([]Document, error) {
+	rendered, err := t.Render(data, "")
+	if err != nil {
+		return nil, err
+	}
+	return Parse(rendered), nil
+}
+
+// ReadFile will render the manifest file and read its objects,
+// see manifest.ReadFile()
+//
+// Args:
+//	- path of the YAML or JSON file
+//
+// Returns:
+//	- slice of Document, in the manifest order, or error
+func (t Template) ReadFile(path string) ([]Document, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, k8errors.Wrap("read manifest "+path, err)
+	}
+	rendered, err := t.Render(data, path)
+	if err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(rendered), path)
+}
+
+// ReadDir will render and read all manifest files of the
+// directory, see manifest.ReadDir()
+//
+// Args:
+//	- path of the directory
+//
+// Returns:
+//	- slice of Document, Index counted across files, or error
+func (t Template) ReadDir(path string) ([]Document, error) {
+	return readDir(path, t.ReadFile)
+}
@@ -0,0 +1,194 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+)
+
+func TestTemplateRender(t *testing.T) {
+	values := map[string]interface{}{"ns": "demo", "replicas": 2}
+
+	tests := []struct {
+		name     string
+		template Template
+		data     string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "go template and variables",
+			template: Template{Values: values},
+			data:     "namespace: {{ .ns }}\nreplicas: ${replicas}\n",
+			want:     "namespace: demo\nreplicas: 2\n",
+		},
+		{
+			name:     "variable default",
+			template: Template{Values: values},
+			data:     "a: ${image:-nginx}\nb: ${ns:-default}\nc: ${empty:-}",
+			want:     "a: nginx\nb: demo\nc: ",
+		},
+		{
+			name:     "escaped variable",
+			template: Template{Values: values},
+			data:     "echo $${ns}",
+			want:     "echo ${ns}",
+		},
+		{
+			name:     "unknown variable is kept",
+			template: Template{Values: values},
+			data:     "script: echo ${HOME} ${ns}",
+			want:     "script: echo ${HOME} demo",
+		},
+		{
+			name:     "unknown variable in strict mode",
+			template: Template{Values: values, Strict: true},
+			data:     "script: echo ${HOME} ${USER} ${ns}",
+			wantErr:  k8errors.ErrInvalidSpec,
+		},
+		{
+			name:     "missing key in the go template",
+			template: Template{Values: values},
+			data:     "namespace: {{ .missing }}",
+			wantErr:  k8errors.ErrInvalidSpec,
+		},
+		{
+			name:     "invalid go template",
+			template: Template{Values: values},
+			data:     "namespace: {{ .ns ",
+			wantErr:  k8errors.ErrInvalidSpec,
+		},
+		{
+			name:     "go mode keeps the variables",
+			template: Template{Values: values, Mode: TemplateGo, Strict: true},
+			data:     "namespace: {{ .ns }}\nreplicas: ${replicas}\n",
+			want:     "namespace: demo\nreplicas: ${replicas}\n",
+		},
+		{
+			name:     "vars mode keeps the go template",
+			template: Template{Values: values, Mode: TemplateVars},
+			data:     "namespace: {{ .missing }}\nreplicas: ${replicas}\n",
+			want:     "namespace: {{ .missing }}\nreplicas: 2\n",
+		},
+		{
+			name:     "functions",
+			template: Template{Values: values},
+			data:     `{{ base64 "k8devel" }} {{ base64Decode "azhkZXZlbA==" }} {{ quote .replicas }} {{ default "x" "" }} {{ default "x" .ns }}`,
+			want:     `azhkZXZlbA== k8devel "2" x demo`,
+		},
+		{
+			name: "custom functions",
+			template: Template{
+				Values: values,
+				Funcs: template.FuncMap{
+					"upper": strings.ToUpper,
+					// replaces a built-in one
+					"quote": func(v interface{}) string { return "'" + v.(string) + "'" },
+				},
+			},
+			data: `{{ upper .ns }} {{ quote .ns }}`,
+			want: `DEMO 'demo'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.template.Render([]byte(tt.data), "test.yaml")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Render() error = %v, want %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateRandomName(t *testing.T) {
+	data := `{{ $ns := randomName "test" }}{{ $ns }} {{ $ns }} {{ randomString 8 }}`
+	got, err := Template{}.Render([]byte(data), "")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	m := regexp.MustCompile(`^(test-[a-z]{5}) (test-[a-z]{5}) [a-z]{8}$`).FindStringSubmatch(string(got))
+	if m == nil || m[1] != m[2] {
+		t.Errorf("Render() = %q, want the same random name twice and a random string", got)
+	}
+}
+
+func TestTemplateReadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.yaml": "{{- range .names }}\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ . }}\n{{- end }}\n",
+		"b.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: ${secret}\n",
+		"c.txt":  "{{ not a manifest",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmpl := Template{Values: map[string]interface{}{
+		"names":  []string{"one", "two"},
+		"secret": "token",
+	}}
+	docs, err := tmpl.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	// lines are the ones of the rendered manifests
+	want := []parsed{
+		{kind: "ConfigMap", name: "one", line: 3},
+		{kind: "ConfigMap", name: "two", line: 8},
+		{kind: "Secret", name: "token", line: 1},
+	}
+	if len(docs) != len(want) {
+		t.Fatalf("ReadDir() = %d documents, want %d: %+v", len(docs), len(want), docs)
+	}
+	for i, doc := range docs {
+		if doc.Err != nil {
+			t.Errorf("document %d: unexpected error: %v", i, doc.Err)
+			continue
+		}
+		if doc.Index != i || doc.Line != want[i].line ||
+			doc.Object.GetKind() != want[i].kind || doc.Object.GetName() != want[i].name {
+			t.Errorf("document %d: %d %s %s at line %d, want %s %s at line %d", i,
+				doc.Index, doc.Object.GetKind(), doc.Object.GetName(), doc.Line,
+				want[i].kind, want[i].name, want[i].line)
+		}
+	}
+
+	if _, err := (Template{Strict: true}).ReadFile(filepath.Join(dir, "b.yaml")); !errors.Is(err, k8errors.ErrInvalidSpec) {
+		t.Errorf("ReadFile() error = %v, want ErrInvalidSpec", err)
+	}
+}
@@ -0,0 +1,131 @@
+package manifest
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+	"github.com/thekubeworld/k8devel/pkg/logger"
+)
+
+// WaitFunc will report if the object of the result is done, with
+// the reason when it's not. The object is nil when the Get
+// failed with err (i.e. NotFound). An error returned means it
+// will never be done, it's set in the result.
+type WaitFunc func(ctx context.Context,
+	r *Result,
+	obj *unstructured.Unstructured,
+	err error) (bool, string, error)
+
+// Waiting is an object not done when Wait() returned
+type Waiting struct {
+	Result *Result
+	Reason string // why it's not done, from the last check
+}
+
+// Wait will get the objects of the results until check reports
+// all of them done, or timeout. The interval and backoff between
+// checks are the ones of the client RetryPolicy, see
+// Client.PollWithTimeout(). Results of objects that can't be got
+// have Err set.
+//
+// Args:
+//	- context for cancellation and deadline
+//	- client struct
+//	- results to wait, pointers to the Results
+//	- timeout for all objects, MaxElapsedTime from the client
+//	  RetryPolicy when zero
+//	- check of every object
+//
+// Returns:
+//	- objects not done after the timeout, with the reason
+func Wait(ctx context.Context,
+	c *client.Client,
+	results []*Result,
+	timeout time.Duration,
+	check WaitFunc) []Waiting {
+
+	type waiting struct {
+		Waiting
+		ri dynamic.ResourceInterface
+	}
+
+	var pending []*waiting
+	for _, r := range results {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(r.GVK)
+		obj.SetNamespace(r.Namespace)
+		obj.SetName(r.Name)
+		ri, _, err := c.ResourceFor(obj)
+		if err != nil {
+			r.Err = err
+			continue
+		}
+		pending = append(pending, &waiting{
+			Waiting: Waiting{Result: r, Reason: "not checked"},
+			ri:      ri,
+		})
+	}
+
+	// objects not done are reported, not the TimeoutError
+	op := fmt.Sprintf("wait %d objects", len(pending))
+	_ = c.PollWithTimeout(ctx, op, timeout, func(ctx context.Context) (bool, error) {
+		remaining := pending[:0]
+		for _, w := range pending {
+			obj, err := w.ri.Get(ctx, w.Result.Name, metav1.GetOptions{})
+			if err != nil && ctx.Err() != nil {
+				// keep the last reason when the context is done
+				remaining = append(remaining, w)
+				continue
+			}
+			if err != nil {
+				obj = nil
+			}
+
+			done, reason, err := check(ctx, w.Result, obj, err)
+			switch {
+			case err != nil:
+				w.Result.Err = err
+			case !done:
+				w.Reason = reason
+				remaining = append(remaining, w)
+			}
+		}
+		pending = remaining
+
+		for _, w := range pending {
+			c.Log(logger.LevelDebug, "Waiting object",
+				"kind", w.Result.GVK.Kind,
+				"object", w.Result.Object(),
+				"reason", w.Reason)
+		}
+		return len(pending) == 0, nil
+	})
+
+	var notDone []Waiting
+	for _, w := range pending {
+		notDone = append(notDone, w.Waiting)
+	}
+	return notDone
+}
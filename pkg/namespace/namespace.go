@@ -23,22 +23,31 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 )
 
 // Delete will delete a namespace
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a Client struct
 //	- namespace name
 //
 // Returns:
 //     error or nil
 //
-func Delete(c *client.Client, namespace string) error {
-	err := c.Clientset.CoreV1().Namespaces().Delete(
-		context.TODO(),
-		namespace,
-		metav1.DeleteOptions{})
+func Delete(ctx context.Context, c *client.Client, namespace string) error {
+	operation := client.Operation{
+		Verb: "delete",
+		Kind: "Namespace",
+		Name: namespace,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.CoreV1().Namespaces().Delete(
+			ctx,
+			namespace,
+			c.DeleteOptions())
+	})
 	if err != nil {
 		return err
 	}
@@ -49,14 +58,15 @@ func Delete(c *client.Client, namespace string) error {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.NamespaceList or error
-func List(c *client.Client) (*v1.NamespaceList, error) {
-	namespaces, err := c.Clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+func List(ctx context.Context, c *client.Client) (*v1.NamespaceList, error) {
+	namespaces, err := c.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("list namespaces", err)
 	}
 	return namespaces, nil
 }
@@ -64,13 +74,14 @@ func List(c *client.Client) (*v1.NamespaceList, error) {
 // Create will create a namespace
 //
 // Args:
-//     - Pointer to a Client struct
+//	- context for cancellation and deadline
+//	- Pointer to a Client struct
 //	- namespace name
 //
 // Returns:
 //     error or nil
 //
-func Create(c *client.Client, namespace string) error {
+func Create(ctx context.Context, c *client.Client, namespace string) error {
 	ns := &v1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: namespace,
@@ -80,10 +91,19 @@ func Create(c *client.Client, namespace string) error {
 		},
 	}
 
-	_, err := c.Clientset.CoreV1().Namespaces().Create(
-		context.TODO(),
-		ns,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:   "create",
+		Kind:   "Namespace",
+		Name:   ns.Name,
+		Object: ns,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Namespaces().Create(
+			ctx,
+			ns,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -93,19 +113,20 @@ func Create(c *client.Client, namespace string) error {
 // Exists will check if the namespace exists or not
 //
 // Args:
-//     - Pointer to a Client struct
+//	- context for cancellation and deadline
+//	- Pointer to a Client struct
 //	- namespace name
 //
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, namespace string) (string, error) {
+func Exists(ctx context.Context, c *client.Client, namespace string) (string, error) {
 	exists, err := c.Clientset.CoreV1().Namespaces().Get(
-		context.TODO(),
+		ctx,
 		namespace,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get namespace "+namespace, err)
 	}
 
 	return exists.Name, nil
@@ -0,0 +1,96 @@
+package namespace
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+)
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	c := client.NewFake()
+
+	if err := Create(ctx, c, "foobar"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ns, err := c.Clientset.CoreV1().Namespaces().Get(ctx, "foobar", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("namespace not created: %v", err)
+	}
+	if ns.Labels["name"] != "foobar" {
+		t.Errorf("label name = %q, want foobar", ns.Labels["name"])
+	}
+
+	err = Create(ctx, c, "foobar")
+	if !apierrors.IsAlreadyExists(err) {
+		t.Errorf("second Create() error = %v, want AlreadyExists", err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	ctx := context.Background()
+	c := client.NewFake(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foobar"}})
+
+	name, err := Exists(ctx, c, "foobar")
+	if err != nil || name != "foobar" {
+		t.Errorf("Exists(foobar) = %q, %v", name, err)
+	}
+
+	_, err = Exists(ctx, c, "missing")
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Exists(missing) error = %v, want NotFound", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	c := client.NewFake(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foobar"}},
+	)
+
+	namespaces, err := List(context.Background(), c)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(namespaces.Items) != 2 {
+		t.Errorf("List() returned %d namespaces, want 2", len(namespaces.Items))
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	c := client.NewFake(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foobar"}})
+
+	if err := Delete(ctx, c, "foobar"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := Exists(ctx, c, "foobar"); !apierrors.IsNotFound(err) {
+		t.Errorf("namespace still exists after Delete(): %v", err)
+	}
+
+	err := Delete(ctx, c, "foobar")
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Delete() of a missing namespace error = %v, want NotFound", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 )
 
 // Instance type refers to the ConfigMap object
@@ -36,14 +37,15 @@ type Instance struct {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.ConfigMapList or error
-func GetIPFromNodes(c *client.Client) ([]string, error) {
-	nodes, err := c.Clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+func GetIPFromNodes(ctx context.Context, c *client.Client) ([]string, error) {
+	nodes, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("list nodes", err)
 	}
 	nodeip := []v1.NodeAddress{}
 	var nodeList []string
@@ -19,16 +19,14 @@ limitations under the License.
 import (
 	"bytes"
 	"context"
-	"errors"
+	"fmt"
 	"strings"
-	"time"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	"github.com/thekubeworld/k8devel/pkg/util"
 
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/remotecommand"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -46,53 +44,30 @@ type Instance struct {
 	LabelValue      string
 }
 
-// ExecCmd executes a command inside a POD
+// ExecCmd executes a command inside a POD via the Executor
+// from the Client struct
 //
 // Args:
+//      ctx - context for cancellation and deadline
 //      Client - struct from client module
 //	podName	- The pod name
 //	cmd - Array (string)
 //
 // Returns:
 //	stdout, stderr as bytes.Buffer or error
-func ExecCmd(c *client.Client,
+func ExecCmd(ctx context.Context,
+	c *client.Client,
 	podName string,
 	nameSpace string,
 	cmd []string) (bytes.Buffer, bytes.Buffer, error) {
 
-	restClient := c.Clientset.CoreV1().RESTClient()
-
-	req := restClient.Post().Resource("pods").Name(podName).
-		Namespace(nameSpace).SubResource("exec")
-	option := &v1.PodExecOptions{
-		Command: cmd,
-		Stdin:   false,
-		Stdout:  true,
-		Stderr:  true,
-		TTY:     true,
+	if c.Executor == nil {
+		var stdout, stderr bytes.Buffer
+		return stdout, stderr, k8errors.New(k8errors.ErrNotConnected,
+			"exec",
+			"client has no executor, call Connect() first")
 	}
-
-	req.VersionedParams(
-		option,
-		scheme.ParameterCodec,
-	)
-	var stdout, stderr bytes.Buffer
-	exec, err := remotecommand.NewSPDYExecutor(
-		c.Restconfig,
-		"POST",
-		req.URL())
-	if err != nil {
-		return stdout, stderr, err
-	}
-	err = exec.Stream(remotecommand.StreamOptions{
-		Stdin:  nil,
-		Stdout: &stdout,
-		Stderr: &stderr,
-	})
-	if err != nil {
-		return stdout, stderr, err
-	}
-	return stdout, stderr, nil
+	return c.Executor.Exec(ctx, podName, nameSpace, cmd)
 }
 
 // GetLastTimeConditionHappened Get the last time a condition
@@ -108,23 +83,25 @@ func ExecCmd(c *client.Client,
 //
 // Args:
 //
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- pod name
 //	- namespace
 //
 // Returns:
 //	- the IP as string or error
-func GetLastTimeConditionHappened(c *client.Client,
+func GetLastTimeConditionHappened(ctx context.Context,
+	c *client.Client,
 	condition string,
 	podName string,
 	nameSpace string) (metav1.Time, error) {
 
 	pod, err := c.Clientset.CoreV1().Pods(nameSpace).Get(
-		context.TODO(),
+		ctx,
 		podName,
 		metav1.GetOptions{})
 	if err != nil {
-		return metav1.Time{}, err
+		return metav1.Time{}, k8errors.Wrap("get pod "+nameSpace+"/"+podName, err)
 	}
 
 	var requiredCondition v1.PodConditionType
@@ -143,9 +120,12 @@ func GetLastTimeConditionHappened(c *client.Client,
 		requiredCondition = v1.PodScheduled
 		break
 	default:
-		return metav1.Time{}, errors.New("condition not recognized, use: " +
-			"ContainersReady, Initialized, " +
-			"Ready or PodScheduled")
+		return metav1.Time{}, k8errors.New(k8errors.ErrInvalidSpec,
+			"get last time condition happened",
+			"condition %q not recognized, use: "+
+				"ContainersReady, Initialized, "+
+				"Ready or PodScheduled",
+			condition)
 	}
 
 	for _, cond := range pod.Status.Conditions {
@@ -170,30 +150,36 @@ func GetLastTimeConditionHappened(c *client.Client,
 			return cond.LastTransitionTime, nil
 		}
 	}
-	return metav1.Time{}, errors.New("unable to get the last time the " +
-		"condition happened")
+	return metav1.Time{}, k8errors.New(k8errors.ErrNotFound,
+		"get last time condition happened",
+		"condition %s is not true in pod %s/%s",
+		condition,
+		nameSpace,
+		podName)
 }
 
 // GetIP will return the pod IP address
 //
 // Args:
 //
+//	- context for cancellation and deadline
 //	- Client struct from client module
 //	- pod name
 //	- namespace
 //
 // Returns:
 //	- the IP as string or error
-func GetIP(c *client.Client,
+func GetIP(ctx context.Context,
+	c *client.Client,
 	podName string,
 	nameSpace string) (string, error) {
 
 	pod, err := c.Clientset.CoreV1().Pods(nameSpace).Get(
-		context.TODO(),
+		ctx,
 		podName,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get pod "+nameSpace+"/"+podName, err)
 	}
 	return pod.Status.PodIP, nil
 
@@ -203,20 +189,25 @@ func GetIP(c *client.Client,
 // substring provided
 //
 // Args:
-//      - Client struct from client module
-//      - substring to be found
-//      - namespace
+//	- context for cancellation and deadline
+//	- Client struct from client module
+//	- substring to be found
+//	- namespace
 //
 // Return:
-//      - error or nil
-func FindPodsWithNameContains(c *client.Client,
+//	- pod names found, number of pods found or error
+func FindPodsWithNameContains(ctx context.Context,
+	c *client.Client,
 	substring string,
-	namespace string) ([]string, int) {
+	namespace string) ([]string, int, error) {
 
 	var podsFound []string
-	listPods, _ := c.Clientset.CoreV1().Pods(namespace).List(
-		context.TODO(),
+	listPods, err := c.Clientset.CoreV1().Pods(namespace).List(
+		ctx,
 		metav1.ListOptions{})
+	if err != nil {
+		return nil, 0, k8errors.Wrap("list pods "+namespace, err)
+	}
 
 	for _, p := range listPods.Items {
 		if strings.Contains(p.Name, substring) {
@@ -224,7 +215,7 @@ func FindPodsWithNameContains(c *client.Client,
 		}
 	}
 
-	return podsFound, len(podsFound)
+	return podsFound, len(podsFound), nil
 }
 
 // isPodRunning will check if the pod is running
@@ -236,10 +227,10 @@ func FindPodsWithNameContains(c *client.Client,
 //
 // Returns:
 //	bool or error
-func isPodRunning(c *client.Client, podname, namespace string) wait.ConditionFunc {
-	return func() (bool, error) {
+func isPodRunning(c *client.Client, podname, namespace string) wait.ConditionWithContextFunc {
+	return func(ctx context.Context) (bool, error) {
 		pod, err := c.Clientset.CoreV1().Pods(namespace).Get(
-			context.TODO(),
+			ctx,
 			podname,
 			metav1.GetOptions{})
 		if err != nil {
@@ -250,42 +241,52 @@ func isPodRunning(c *client.Client, podname, namespace string) wait.ConditionFun
 		case v1.PodRunning:
 			return true, nil
 		case v1.PodFailed, v1.PodSucceeded:
-			return false, errors.New("pod not running")
+			return false, fmt.Errorf("pod %s/%s not running, phase %s",
+				namespace,
+				podname,
+				pod.Status.Phase)
 		}
 		return false, nil
 	}
 }
 
-// waitForPodRunning will execute wait.PollImmediate
+// waitForPodRunning will poll the pod status based on the
+// RetryPolicy from the client
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a client struct
 //	- podname
 //	- namespace
 //
 // Returns:
 //	nil or error
-func waitForPodRunning(c *client.Client, namespace, podname string, timeout time.Duration) error {
-	return wait.PollImmediate(
-		time.Second,
-		timeout,
+func waitForPodRunning(ctx context.Context, c *client.Client, namespace, podname string) error {
+	return c.Poll(
+		ctx,
+		"wait pod "+namespace+"/"+podname+" running",
 		isPodRunning(c, podname, namespace))
 }
 
 // WaitForPodInRunningState will execute waitForPodRunning
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a client struct
 //	- podname
 //	- namespace
 //
 // Returns:
 //	nil or error
-func WaitForPodInRunningState(c *client.Client, podname string, namespace string) error {
-	if err := waitForPodRunning(c,
+func WaitForPodInRunningState(ctx context.Context, c *client.Client, podname string, namespace string) error {
+	// Pod is never created in dry-run
+	if c.IsDryRun() {
+		return nil
+	}
+
+	if err := waitForPodRunning(ctx, c,
 		namespace,
-		podname,
-		time.Duration(c.TimeoutTaskInSec)*time.Second); err != nil {
+		podname); err != nil {
 		return err
 	}
 	return nil
@@ -294,16 +295,17 @@ func WaitForPodInRunningState(c *client.Client, podname string, namespace string
 // Exists will check if the pod exists or not
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, podName string, namespace string) (string, error) {
-	exists, err := c.Clientset.CoreV1().Services(namespace).
-		Get(context.TODO(), podName, metav1.GetOptions{})
+func Exists(ctx context.Context, c *client.Client, podName string, namespace string) (string, error) {
+	exists, err := c.Clientset.CoreV1().Pods(namespace).
+		Get(ctx, podName, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get pod "+namespace+"/"+podName, err)
 	}
 
 	return exists.Name, nil
@@ -313,18 +315,19 @@ func Exists(c *client.Client, podName string, namespace string) (string, error)
 // the pod be in running state
 //
 // Args:
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //      - Instance struct from pod module
 //
 // Return:
 //      - error or nil
-func CreateWaitRunningState(c *client.Client, p *Instance) error {
-	err := Create(c, p)
+func CreateWaitRunningState(ctx context.Context, c *client.Client, p *Instance) error {
+	err := Create(ctx, c, p)
 	if err != nil {
 		return err
 	}
 
-	err = WaitForPodInRunningState(c, p.Name, p.Namespace)
+	err = WaitForPodInRunningState(ctx, c, p.Name, p.Namespace)
 	if err != nil {
 		return err
 	}
@@ -335,12 +338,13 @@ func CreateWaitRunningState(c *client.Client, p *Instance) error {
 // Create will create a POD
 //
 // Args:
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //      - Instance struct from pod module
 //
 // Return:
 //      - error or nil
-func Create(c *client.Client, p *Instance) error {
+func Create(ctx context.Context, c *client.Client, p *Instance) error {
 
 	// ImagePullPolicy is optional
 	// By default, the kubelet tries to pull each image from the specified
@@ -374,10 +378,20 @@ func Create(c *client.Client, p *Instance) error {
 		},
 	}
 
-	_, err = c.Clientset.CoreV1().Pods(p.Namespace).Create(
-		context.TODO(),
-		pod,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Pod",
+		Namespace: p.Namespace,
+		Name:      pod.Name,
+		Object:    pod,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Pods(p.Namespace).Create(
+			ctx,
+			pod,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
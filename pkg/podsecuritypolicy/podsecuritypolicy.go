@@ -18,7 +18,6 @@ limitations under the License.
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
@@ -26,80 +25,45 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 )
 
 // ListAllPodSecurityPolicy will list all PodSecurityPolicoies
 //
 // Args:
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Return:
-//      - error or nil
-func ListAllPodSecurityPolicy(c *client.Client) error {
+//      - pointer v1beta1.PodSecurityPolicyList or error
+func ListAllPodSecurityPolicy(ctx context.Context, c *client.Client) (*v1beta1.PodSecurityPolicyList, error) {
+	c.Log(logger.LevelInfo, "Listing podsecuritypolicies")
 	psp, err := c.Clientset.PolicyV1beta1().PodSecurityPolicies().List(
-		context.TODO(),
+		ctx,
 		metav1.ListOptions{})
 	if err != nil {
-		return errors.New("Error listing PodSecurityPolicies")
+		return nil, k8errors.Wrap("list podsecuritypolicies", err)
 	}
 
 	if psp == nil || len(psp.Items) == 0 {
-		return errors.New("no PodSecurityPolicies found; assuming PodSecurityPolicy is disabled")
+		return nil, k8errors.New(k8errors.ErrNotFound,
+			"list podsecuritypolicies",
+			"no PodSecurityPolicies found; assuming PodSecurityPolicy is disabled")
 	}
 	for i := 0; i < len(psp.Items); i++ {
-		fmt.Println("")
-		fmt.Println("Name:", psp.Items[i].Name)
-		fmt.Println("\tPrivileged:", psp.Items[i].Spec.Privileged)
-
-		if psp.Items[i].Spec.AllowPrivilegeEscalation != nil {
-			fmt.Println("\tAllow PrivilegeEscalation:", *psp.Items[i].Spec.AllowPrivilegeEscalation)
-		} else {
-			fmt.Println("\tAllow PrivilegeEscalation: unset")
-		}
-
-		fmt.Println("\tRequired DropCapabilities:", psp.Items[i].Spec.RequiredDropCapabilities)
-		fmt.Println("\tDefault AddCapabilities:", psp.Items[i].Spec.DefaultAddCapabilities)
-		fmt.Println("\tRequired DropCapabilities:", psp.Items[i].Spec.RequiredDropCapabilities)
-		fmt.Println("\tAllowed Capabilities:", psp.Items[i].Spec.AllowedCapabilities)
-		fmt.Println("\tVolumes:", psp.Items[i].Spec.Volumes)
-
-		if len(psp.Items[i].Spec.AllowedFlexVolumes) > 0 {
-			fmt.Println("\tAllowed FlexVolumes:", psp.Items[i].Spec.AllowedFlexVolumes)
-		}
-		fmt.Println("\tAllowed CSIDrivers:", psp.Items[i].Spec.AllowedCSIDrivers)
-		fmt.Println("\tAllowed UnsafeSysctls:", psp.Items[i].Spec.AllowedUnsafeSysctls)
-		fmt.Println("\tForbidden Sysctls:", psp.Items[i].Spec.ForbiddenSysctls)
-		fmt.Println("\tAllow Host Network:", psp.Items[i].Spec.HostNetwork)
-		fmt.Println("\tAllow Host Ports:", psp.Items[i].Spec.HostPorts)
-		fmt.Println("\tAllow Host PID:", psp.Items[i].Spec.HostPID)
-		fmt.Println("\tAllow Host IPC:", psp.Items[i].Spec.HostIPC)
-		fmt.Println("\tRead Only Root Filesystem:", psp.Items[i].Spec.ReadOnlyRootFilesystem)
-		fmt.Println("\tSELinux Context Strategy:", psp.Items[i].Spec.SELinux.Rule)
-		if psp.Items[i].Spec.SELinux.SELinuxOptions != nil {
-			fmt.Println("\t\tUser:", psp.Items[i].Spec.SELinux.SELinuxOptions.User)
-			fmt.Println("\t\tRole:", psp.Items[i].Spec.SELinux.SELinuxOptions.Role)
-			fmt.Println("\t\tSELinux Type:", psp.Items[i].Spec.SELinux.SELinuxOptions.Type)
-			fmt.Println("\t\tLevel:", psp.Items[i].Spec.SELinux.SELinuxOptions.Level)
-		}
-		fmt.Println("\tRun As User Strategy:", psp.Items[i].Spec.RunAsUser.Rule)
-		fmt.Println("\tRanges: ", psp.Items[i].Spec.RunAsUser.Ranges)
-
-		fmt.Println("\tFSGroup Strategy:", psp.Items[i].Spec.FSGroup.Rule)
-		fmt.Println("\tRanges:", psp.Items[i].Spec.FSGroup.Ranges)
-
-		fmt.Println("\tSupplemental Groups Strategy:", psp.Items[i].Spec.SupplementalGroups.Rule)
-		fmt.Println("\tRanges:", idRangeToString(psp.Items[i].Spec.SupplementalGroups.Ranges))
+		c.Log(logger.LevelDebug, "PodSecurityPolicy", "name", psp.Items[i].Name)
 	}
-	return nil
+	return psp, nil
 }
 
-// idRangeToString will return string from idRange
+// IDRangeToString will return string from idRange
 // Params:
 // 	[]v1beta1.IDRange
 //
 // Returns
 //	string
-func idRangeToString(ranges []v1beta1.IDRange) string {
+func IDRangeToString(ranges []v1beta1.IDRange) string {
 	formattedString := ""
 	if ranges != nil {
 		strRanges := []string{}
@@ -18,13 +18,13 @@ limitations under the License.
 
 import (
 	"context"
-	"fmt"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
 
@@ -44,15 +44,24 @@ type Instance struct {
 // Delete will delete a pvc
 //
 // Args:
-//      - Pointer to a Client struct
-//      - namespace name
+//	- context for cancellation and deadline
+//	- Pointer to a Client struct
+//	- namespace name
 //	- pvc name
 //
 // Returns:
 //     error or nil
 //
-func Delete(c *client.Client, namespace string, pvcname string) error {
-	err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(context.TODO(), pvcname, metav1.DeleteOptions{})
+func Delete(ctx context.Context, c *client.Client, namespace string, pvcname string) error {
+	operation := client.Operation{
+		Verb:      "delete",
+		Kind:      "PersistentVolumeClaim",
+		Namespace: namespace,
+		Name:      pvcname,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvcname, c.DeleteOptions())
+	})
 	if err != nil {
 		return err
 	}
@@ -62,22 +71,27 @@ func Delete(c *client.Client, namespace string, pvcname string) error {
 // Create will create a PVC
 //
 // Args:
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //      - Instance struct from pod module
 //
 // Return:
 //      - error or nil
-func Create(c *client.Client, p *Instance) (*v1.PersistentVolumeClaim, error) {
+func Create(ctx context.Context, c *client.Client, p *Instance) (*v1.PersistentVolumeClaim, error) {
 
 	volumeMode, _ := util.DetectVolumeMode(p.VolumeMode)
 
 	if len(p.ClaimSize) == 0 {
-		return nil, fmt.Errorf("size is required for a PVC")
+		return nil, k8errors.New(k8errors.ErrInvalidSpec,
+			"create pvc",
+			"size is required for a PVC")
 	}
 
 	accessModes, err := util.DetectVolumeAccessModes(p.AccessModes)
 	if len(p.AccessModes) == 0 {
-		return nil, fmt.Errorf("accessMode is required for a PVC")
+		return nil, k8errors.New(k8errors.ErrInvalidSpec,
+			"create pvc",
+			"accessMode is required for a PVC")
 	}
 
 	if len(p.NamePrefix) == 0 {
@@ -104,9 +118,22 @@ func Create(c *client.Client, p *Instance) (*v1.PersistentVolumeClaim, error) {
 		},
 	}
 
-	pvc, err := c.Clientset.CoreV1().PersistentVolumeClaims(p.Namespace).Create(context.TODO(), pvcSpec, metav1.CreateOptions{})
+	// In client dry-run the rendered object is returned
+	pvc := pvcSpec
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "PersistentVolumeClaim",
+		Namespace: p.Namespace,
+		Name:      p.Name,
+		Object:    pvcSpec,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		var err error
+		pvc, err = c.Clientset.CoreV1().PersistentVolumeClaims(p.Namespace).Create(ctx, pvcSpec, c.CreateOptions())
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("PVC Create API error: %v", err)
+		return nil, err
 	}
 	return pvc, nil
 }
@@ -115,14 +142,15 @@ func Create(c *client.Client, p *Instance) (*v1.PersistentVolumeClaim, error) {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.NamespaceList or error
-func List(c *client.Client, namespace string) (*v1.PersistentVolumeClaimList, error) {
-	pvc, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+func List(ctx context.Context, c *client.Client, namespace string) (*v1.PersistentVolumeClaimList, error) {
+	pvc, err := c.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("list persistentvolumeclaims in "+namespace, err)
 	}
 	return pvc, nil
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -38,17 +39,25 @@ type Instance struct {
 // Delete will delete a clusterrole
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a Client struct
 //	- clusterrolename name
 //
 // Returns:
 //     error or nil
 //
-func Delete(c *client.Client, clusterrolename string) error {
-	err := c.Clientset.RbacV1().ClusterRoles().Delete(
-		context.TODO(),
-		clusterrolename,
-		metav1.DeleteOptions{})
+func Delete(ctx context.Context, c *client.Client, clusterrolename string) error {
+	operation := client.Operation{
+		Verb: "delete",
+		Kind: "ClusterRole",
+		Name: clusterrolename,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.RbacV1().ClusterRoles().Delete(
+			ctx,
+			clusterrolename,
+			c.DeleteOptions())
+	})
 	if err != nil {
 		return err
 	}
@@ -59,14 +68,15 @@ func Delete(c *client.Client, clusterrolename string) error {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.NamespaceList or error
-func List(c *client.Client) (*rbacv1.ClusterRoleList, error) {
-	clusterrolelist, err := c.Clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+func List(ctx context.Context, c *client.Client) (*rbacv1.ClusterRoleList, error) {
+	clusterrolelist, err := c.Clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("list clusterroles", err)
 	}
 	return clusterrolelist, nil
 }
@@ -74,13 +84,14 @@ func List(c *client.Client) (*rbacv1.ClusterRoleList, error) {
 // Create will create a clusterrole
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //     - Pointer to Instance
 //
 // Returns:
 //     error or nil
 //
-func Create(c *client.Client, cr *Instance) error {
+func Create(ctx context.Context, c *client.Client, cr *Instance) error {
 
 	role := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -99,10 +110,19 @@ func Create(c *client.Client, cr *Instance) error {
 		},
 	}
 
-	_, err := c.Clientset.RbacV1().ClusterRoles().Create(
-		context.TODO(),
-		role,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:   "create",
+		Kind:   "ClusterRole",
+		Name:   role.Name,
+		Object: role,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.RbacV1().ClusterRoles().Create(
+			ctx,
+			role,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -112,19 +132,20 @@ func Create(c *client.Client, cr *Instance) error {
 // Exists will check if the namespace exists or not
 //
 // Args:
-//     - Pointer to a Client struct
+//	- context for cancellation and deadline
+//	- Pointer to a Client struct
 //	- namespace name
 //
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, namespace string) (string, error) {
+func Exists(ctx context.Context, c *client.Client, namespace string) (string, error) {
 	exists, err := c.Clientset.CoreV1().Namespaces().Get(
-		context.TODO(),
+		ctx,
 		namespace,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get namespace "+namespace, err)
 	}
 
 	return exists.Name, nil
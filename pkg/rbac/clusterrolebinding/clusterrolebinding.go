@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -41,17 +42,25 @@ type Instance struct {
 // Delete will delete a ClusteRroleBinding
 //
 // Args:
+//	- context for cancellation and deadline
 //	- Pointer to a Client struct
 //	- clusterrolebinding name
 //
 // Returns:
 //     error or nil
 //
-func Delete(c *client.Client, clusterrolebindingname string) error {
-	err := c.Clientset.RbacV1().ClusterRoleBindings().Delete(
-		context.TODO(),
-		clusterrolebindingname,
-		metav1.DeleteOptions{})
+func Delete(ctx context.Context, c *client.Client, clusterrolebindingname string) error {
+	operation := client.Operation{
+		Verb: "delete",
+		Kind: "ClusterRoleBinding",
+		Name: clusterrolebindingname,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.RbacV1().ClusterRoleBindings().Delete(
+			ctx,
+			clusterrolebindingname,
+			c.DeleteOptions())
+	})
 	if err != nil {
 		return err
 	}
@@ -62,14 +71,15 @@ func Delete(c *client.Client, clusterrolebindingname string) error {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //
 // Returns:
 //      - pointer v1.NamespaceList or error
-func List(c *client.Client) (*rbacv1.ClusterRoleBindingList, error) {
-	clusterrolebindinglist, err := c.Clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+func List(ctx context.Context, c *client.Client) (*rbacv1.ClusterRoleBindingList, error) {
+	clusterrolebindinglist, err := c.Clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return nil, err
+		return nil, k8errors.Wrap("list clusterrolebindings", err)
 	}
 	return clusterrolebindinglist, nil
 }
@@ -77,13 +87,14 @@ func List(c *client.Client) (*rbacv1.ClusterRoleBindingList, error) {
 // Create will create a ClusterRoleBinding
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //     - Pointer to Instance
 //
 // Returns:
 //     error or nil
 //
-func Create(c *client.Client, crb *Instance) error {
+func Create(ctx context.Context, c *client.Client, crb *Instance) error {
 
 	role := &rbacv1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
@@ -107,10 +118,19 @@ func Create(c *client.Client, crb *Instance) error {
 		},
 	}
 
-	_, err := c.Clientset.RbacV1().ClusterRoleBindings().Create(
-		context.TODO(),
-		role,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:   "create",
+		Kind:   "ClusterRoleBinding",
+		Name:   role.Name,
+		Object: role,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.RbacV1().ClusterRoleBindings().Create(
+			ctx,
+			role,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
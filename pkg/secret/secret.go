@@ -18,13 +18,13 @@ limitations under the License.
 
 import (
 	"context"
-	"errors"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 )
 
 // Instance struct for secret
@@ -49,13 +49,14 @@ type Instance struct {
 // Create will create a secret
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //     - Point to the Instance struct
 //
 // Returns:
 //     error or nil
 //
-func Create(c *client.Client, i *Instance) error {
+func Create(ctx context.Context, c *client.Client, i *Instance) error {
 	secretType, err := detectSecretType(i.Type)
 	if err != nil {
 		return err
@@ -71,10 +72,20 @@ func Create(c *client.Client, i *Instance) error {
 		},
 		Type: secretType,
 	}
-	_, err = c.Clientset.CoreV1().Secrets(i.Namespace).Create(
-		context.TODO(),
-		&secret,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Secret",
+		Namespace: i.Namespace,
+		Name:      secret.Name,
+		Object:    &secret,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Secrets(i.Namespace).Create(
+			ctx,
+			&secret,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -107,12 +118,16 @@ func detectSecretType(s string) (v1.SecretType, error) {
 	case "kubernetes.io/dockerconfigjson":
 		return v1.SecretTypeDockerConfigJson, nil
 	}
-	return "", errors.New("unknown secretType yet")
+	return "", k8errors.New(k8errors.ErrInvalidSpec,
+		"detect secret type",
+		"unknown secret type %q",
+		s)
 }
 
 // Exists will check if thsee secret  exists or not
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //     - secret name
 //      - namespace name
@@ -120,13 +135,13 @@ func detectSecretType(s string) (v1.SecretType, error) {
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, secretname string, namespace string) (string, error) {
+func Exists(ctx context.Context, c *client.Client, secretname string, namespace string) (string, error) {
 	exists, err := c.Clientset.CoreV1().Secrets(namespace).Get(
-		context.TODO(),
+		ctx,
 		secretname,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get secret "+namespace+"/"+secretname, err)
 	}
 
 	return exists.Name, nil
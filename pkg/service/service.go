@@ -18,14 +18,15 @@ limitations under the License.
 
 import (
 	"context"
-	"fmt"
-	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 	"github.com/thekubeworld/k8devel/pkg/util"
 )
 
@@ -63,41 +64,61 @@ type Instance struct {
 // Delete deletes a service
 //
 // Args:
+//	ctx - context for cancellation and deadline
 //	Client - client struct from the client module
 //	service - Service Name
 //	namespace - Namespace
 //
 //   Returns:
 //      error or nil
-func Delete(c *client.Client, service string, namespace string) error {
+func Delete(ctx context.Context, c *client.Client, service string, namespace string) error {
 	_, err := c.Clientset.CoreV1().Services(namespace).
-		Get(context.TODO(), service, metav1.GetOptions{})
+		Get(ctx, service, metav1.GetOptions{})
 	if err != nil {
+		return k8errors.Wrap("get service "+namespace+"/"+service, err)
+	}
+
+	c.Log(logger.LevelInfo, "Deleting service",
+		"name", service,
+		"namespace", namespace)
+
+	operation := client.Operation{
+		Verb:      "delete",
+		Kind:      "Service",
+		Namespace: namespace,
+		Name:      service,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		return c.Clientset.CoreV1().Services(namespace).Delete(
+			ctx,
+			service,
+			c.DeleteOptions())
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
-	fmt.Printf("Deleting service: %s namespace: %s...",
-		service,
-		namespace)
+	// Nothing is removed in dry-run
+	if c.IsDryRun() {
+		return nil
+	}
 
 	// Double check service is removed
-	// TODO: We can improve this logic with some pool schema
-	for i := 0; i < c.NumberMaxOfAttemptsPerTask; i++ {
-		_, err := Exists(c, service, namespace)
-		if err != nil {
-			fmt.Printf("Deleted service: %s namespace: %s",
-				service,
-				namespace)
-			break
+	err = c.Poll(ctx, operation.String(), func(ctx context.Context) (bool, error) {
+		_, err := Exists(ctx, c, service, namespace)
+		if apierrors.IsNotFound(err) {
+			return true, nil
 		}
-		c.Clientset.CoreV1().Services(namespace).Delete(
-			context.TODO(),
-			service,
-			metav1.DeleteOptions{})
-
-		time.Sleep(time.Duration(c.TimeoutTaskInSec) * time.Second)
+		return false, err
+	})
+	if err != nil {
+		return err
 	}
 
+	c.Log(logger.LevelInfo, "Deleted service",
+		"name", service,
+		"namespace", namespace)
+
 	return nil
 }
 
@@ -130,22 +151,24 @@ func Delete(c *client.Client, service string, namespace string) error {
 //
 // Args:
 //
+//      - context for cancellation and deadline
 //      - Client struct from client module
 //      - pod name
 //      - namespace
 //
 // Returns:
 //      - the IP as string or error
-func GetIP(c *client.Client,
+func GetIP(ctx context.Context,
+	c *client.Client,
 	svcName string,
 	nameSpace string) (string, error) {
 
 	svc, err := c.Clientset.CoreV1().Services(nameSpace).Get(
-		context.TODO(),
+		ctx,
 		svcName,
 		metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get service "+nameSpace+"/"+svcName, err)
 	}
 	return svc.Spec.ClusterIP, nil
 
@@ -154,16 +177,17 @@ func GetIP(c *client.Client,
 // Exists will check if the service exists or not
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //
 // Returns:
 //     string (namespace name) OR error type
 //
-func Exists(c *client.Client, service string, namespace string) (string, error) {
+func Exists(ctx context.Context, c *client.Client, service string, namespace string) (string, error) {
 	exists, err := c.Clientset.CoreV1().Services(namespace).
-		Get(context.TODO(), service, metav1.GetOptions{})
+		Get(ctx, service, metav1.GetOptions{})
 	if err != nil {
-		return "", err
+		return "", k8errors.Wrap("get service "+namespace+"/"+service, err)
 	}
 
 	return exists.Name, nil
@@ -173,12 +197,13 @@ func Exists(c *client.Client, service string, namespace string) (string, error)
 // from the Service struct via the Client.Clientset
 //
 // Args:
+//    ctx - context for cancellation and deadline
 //    Service - Service struct
 //    Client  - Client struct
 //
 //   Returns:
 //      error or nil
-func CreateClusterIP(c *client.Client, s *Instance) error {
+func CreateClusterIP(ctx context.Context, c *client.Client, s *Instance) error {
 	service := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      s.Name,
@@ -200,10 +225,20 @@ func CreateClusterIP(c *client.Client, s *Instance) error {
 		},
 	}
 
-	_, err := c.Clientset.CoreV1().Services(s.Namespace).Create(
-		context.TODO(),
-		service,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Service",
+		Namespace: s.Namespace,
+		Name:      service.Name,
+		Object:    service,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Services(s.Namespace).Create(
+			ctx,
+			service,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -214,12 +249,13 @@ func CreateClusterIP(c *client.Client, s *Instance) error {
 // from the Service struct via the Client.Clientset
 //
 // Args:
+//    ctx - context for cancellation and deadline
 //    Service - Service struct
 //    Client  - Client struct
 //
 //   Returns:
 //      error or nil
-func CreateNodePort(c *client.Client, s *Instance) error {
+func CreateNodePort(ctx context.Context, c *client.Client, s *Instance) error {
 	serviceProtocol, err := util.DetectContainerPortProtocol(s.PortProtocol)
 	if err != nil {
 		return err
@@ -255,10 +291,20 @@ func CreateNodePort(c *client.Client, s *Instance) error {
 		service.Spec.IPFamilyPolicy = &requireDual
 	}
 
-	_, err = c.Clientset.CoreV1().Services(s.Namespace).Create(
-		context.TODO(),
-		service,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Service",
+		Namespace: s.Namespace,
+		Name:      service.Name,
+		Object:    service,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Services(s.Namespace).Create(
+			ctx,
+			service,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -269,12 +315,13 @@ func CreateNodePort(c *client.Client, s *Instance) error {
 // from the Service struct via the Client.Clientset
 //
 // Args:
+//    ctx - context for cancellation and deadline
 //    Service - Service struct
 //    Client  - Client struct
 //
 //   Returns:
 //      error or nil
-func CreateLoadBalancer(c *client.Client, s *Instance) error {
+func CreateLoadBalancer(ctx context.Context, c *client.Client, s *Instance) error {
 	serviceProtocol, err := util.DetectContainerPortProtocol(s.PortProtocol)
 	if err != nil {
 		return err
@@ -308,10 +355,20 @@ func CreateLoadBalancer(c *client.Client, s *Instance) error {
 		service.Spec.IPFamilyPolicy = &requireDual
 	}
 
-	_, err = c.Clientset.CoreV1().Services(s.Namespace).Create(
-		context.TODO(),
-		service,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Service",
+		Namespace: s.Namespace,
+		Name:      service.Name,
+		Object:    service,
+	}
+	err = c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Services(s.Namespace).Create(
+			ctx,
+			service,
+			c.CreateOptions())
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -322,12 +379,13 @@ func CreateLoadBalancer(c *client.Client, s *Instance) error {
 // from the Service struct via the Client.Clientset
 //
 // Args:
+//    ctx - context for cancellation and deadline
 //    Service - Service struct
 //    Client  - Client struct
 //
 //   Returns:
 //      error or nil
-func CreateExternalName(c *client.Client, s *Instance) error {
+func CreateExternalName(ctx context.Context, c *client.Client, s *Instance) error {
 	service := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      s.Name,
@@ -338,6 +396,15 @@ func CreateExternalName(c *client.Client, s *Instance) error {
 			ExternalName: s.ExternalName},
 	}
 
-	_, err := c.Clientset.CoreV1().Services(s.Namespace).Create(context.TODO(), service, metav1.CreateOptions{})
-	return err
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "Service",
+		Namespace: s.Namespace,
+		Name:      service.Name,
+		Object:    service,
+	}
+	return c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().Services(s.Namespace).Create(ctx, service, c.CreateOptions())
+		return err
+	})
 }
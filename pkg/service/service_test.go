@@ -0,0 +1,160 @@
+package service
+
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/thekubeworld/k8devel/pkg/client"
+)
+
+func newInstance(name string) *Instance {
+	return &Instance{
+		Name:          name,
+		Namespace:     "default",
+		LabelKey:      "app",
+		LabelValue:    name,
+		Port:          80,
+		PortName:      "http",
+		PortProtocol:  "tcp",
+		SelectorKey:   "app",
+		SelectorValue: name,
+		TargetPort:    8080,
+		NodePort:      30080,
+		ExternalName:  "example.com",
+	}
+}
+
+func getService(t *testing.T, c *client.Client, name string) *v1.Service {
+	t.Helper()
+	svc, err := c.Clientset.CoreV1().Services("default").Get(
+		context.Background(),
+		name,
+		metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("service %s not created: %v", name, err)
+	}
+	return svc
+}
+
+func TestCreateClusterIP(t *testing.T) {
+	c := client.NewFake()
+	s := newInstance("web")
+	s.ClusterIP = "10.0.0.10"
+
+	if err := CreateClusterIP(context.Background(), c, s); err != nil {
+		t.Fatalf("CreateClusterIP() error = %v", err)
+	}
+	svc := getService(t, c, "web")
+	if svc.Spec.ClusterIP != "10.0.0.10" {
+		t.Errorf("ClusterIP = %q, want 10.0.0.10", svc.Spec.ClusterIP)
+	}
+	if svc.Spec.Selector["app"] != "web" {
+		t.Errorf("Selector = %v, want app=web", svc.Spec.Selector)
+	}
+
+	err := CreateClusterIP(context.Background(), c, s)
+	if !apierrors.IsAlreadyExists(err) {
+		t.Errorf("second CreateClusterIP() error = %v, want AlreadyExists", err)
+	}
+}
+
+func TestCreateNodePort(t *testing.T) {
+	c := client.NewFake()
+
+	if err := CreateNodePort(context.Background(), c, newInstance("web")); err != nil {
+		t.Fatalf("CreateNodePort() error = %v", err)
+	}
+	svc := getService(t, c, "web")
+	if svc.Spec.Type != v1.ServiceTypeNodePort {
+		t.Errorf("Type = %q, want %q", svc.Spec.Type, v1.ServiceTypeNodePort)
+	}
+	port := svc.Spec.Ports[0]
+	if port.NodePort != 30080 || port.TargetPort.IntValue() != 8080 || port.Protocol != v1.ProtocolTCP {
+		t.Errorf("Ports[0] = %+v", port)
+	}
+
+	s := newInstance("db")
+	s.PortProtocol = "sctp"
+	if err := CreateNodePort(context.Background(), c, s); err == nil {
+		t.Error("CreateNodePort() with an unknown protocol succeeded")
+	}
+}
+
+func TestCreateLoadBalancer(t *testing.T) {
+	c := client.NewFake()
+
+	if err := CreateLoadBalancer(context.Background(), c, newInstance("web")); err != nil {
+		t.Fatalf("CreateLoadBalancer() error = %v", err)
+	}
+	if svc := getService(t, c, "web"); svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		t.Errorf("Type = %q, want %q", svc.Spec.Type, v1.ServiceTypeLoadBalancer)
+	}
+}
+
+func TestCreateExternalName(t *testing.T) {
+	c := client.NewFake()
+
+	if err := CreateExternalName(context.Background(), c, newInstance("web")); err != nil {
+		t.Fatalf("CreateExternalName() error = %v", err)
+	}
+	svc := getService(t, c, "web")
+	if svc.Spec.Type != v1.ServiceTypeExternalName || svc.Spec.ExternalName != "example.com" {
+		t.Errorf("Spec = %+v, want ExternalName example.com", svc.Spec)
+	}
+}
+
+func TestGetIP(t *testing.T) {
+	c := client.NewFake(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{ClusterIP: "10.0.0.10"},
+	})
+
+	ip, err := GetIP(context.Background(), c, "web", "default")
+	if err != nil || ip != "10.0.0.10" {
+		t.Errorf("GetIP(web) = %q, %v", ip, err)
+	}
+
+	_, err = GetIP(context.Background(), c, "missing", "default")
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("GetIP(missing) error = %v, want NotFound", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	c := client.NewFake(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+	})
+
+	if err := Delete(ctx, c, "web", "default"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := Exists(ctx, c, "web", "default"); !apierrors.IsNotFound(err) {
+		t.Errorf("service still exists after Delete(): %v", err)
+	}
+
+	err := Delete(ctx, c, "web", "default")
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Delete() of a missing service error = %v, want NotFound", err)
+	}
+}
@@ -19,10 +19,12 @@ limitations under the License.
 import (
 	"context"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/thekubeworld/k8devel/pkg/client"
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
 )
 
 // Instance holds values for serviceaccount
@@ -35,13 +37,14 @@ type Instance struct {
 // Create will create a new serviceaccount
 //
 // Args:
+//     - context for cancellation and deadline
 //     - Pointer to a Client struct
 //     - Instance structure
 //
 // Returns:
 //     error or nil
 //
-func Create(c *client.Client, i *Instance) error {
+func Create(ctx context.Context, c *client.Client, i *Instance) error {
 
 	// by defaut we set AutomountServiceAccountToken as true
 	autoservice := true
@@ -56,10 +59,103 @@ func Create(c *client.Client, i *Instance) error {
 		},
 		AutomountServiceAccountToken: &autoservice,
 	}
-	_, err := c.Clientset.CoreV1().ServiceAccounts(i.Namespace).Create(
-		context.TODO(),
-		SA,
-		metav1.CreateOptions{})
+	operation := client.Operation{
+		Verb:      "create",
+		Kind:      "ServiceAccount",
+		Namespace: i.Namespace,
+		Name:      SA.Name,
+		Object:    SA,
+	}
+	err := c.Do(ctx, operation, func(ctx context.Context) error {
+		_, err := c.Clientset.CoreV1().ServiceAccounts(i.Namespace).Create(
+			ctx,
+			SA,
+			c.CreateOptions())
+		return err
+	})
 
 	return err
 }
+
+// CreateToken will request a token for the serviceaccount via
+// the TokenRequest API, the token is not stored in a secret
+//
+// Args:
+//     - context for cancellation and deadline
+//     - Pointer to a Client struct
+//     - namespace
+//     - serviceaccount name
+//     - expiration in seconds, 0 uses the API Server default
+//
+// Returns:
+//     token or error
+//
+func CreateToken(ctx context.Context,
+	c *client.Client,
+	namespace string,
+	name string,
+	expirationSeconds int64) (string, error) {
+
+	tokenRequest := &authenticationv1.TokenRequest{}
+	if expirationSeconds > 0 {
+		tokenRequest.Spec.ExpirationSeconds = &expirationSeconds
+	}
+
+	token, err := c.Clientset.CoreV1().ServiceAccounts(namespace).CreateToken(
+		ctx,
+		name,
+		tokenRequest,
+		metav1.CreateOptions{})
+	if err != nil {
+		return "", k8errors.Wrap("create token serviceaccount "+namespace+"/"+name, err)
+	}
+	return token.Status.Token, nil
+}
+
+// NewClient will connect a new Client authenticated with a token
+// of the serviceaccount, same API Server and CA as c. Unlike
+// c.AsServiceAccount() no impersonation rights are required.
+//
+// Args:
+//     - context for cancellation and deadline
+//     - Pointer to a Client struct
+//     - namespace
+//     - serviceaccount name
+//
+// Returns:
+//     Pointer to a Client struct or error
+//
+func NewClient(ctx context.Context,
+	c *client.Client,
+	namespace string,
+	name string) (*client.Client, error) {
+
+	if c.Restconfig == nil {
+		return nil, k8errors.New(k8errors.ErrNotConnected,
+			"new client serviceaccount "+namespace+"/"+name,
+			"Connect() must be called before")
+	}
+
+	token, err := CreateToken(ctx, c, namespace, name, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	saClient := &client.Client{
+		Namespace:                  namespace,
+		TimeoutTaskInSec:           c.TimeoutTaskInSec,
+		NumberMaxOfAttemptsPerTask: c.NumberMaxOfAttemptsPerTask,
+		RetryPolicy:                c.RetryPolicy,
+		Logger:                     c.Logger,
+		DryRun:                     c.DryRun,
+		Journal:                    c.Journal,
+		Credentials: client.Credentials{
+			Host:        c.Restconfig.Host,
+			BearerToken: token,
+			CAFile:      c.Restconfig.CAFile,
+			CAData:      c.Restconfig.CAData,
+			Insecure:    c.Restconfig.Insecure,
+		},
+	}
+	return saClient.Connect()
+}
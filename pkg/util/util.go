@@ -18,8 +18,7 @@ limitations under the License.
 
 import (
 	"bytes"
-	"errors"
-	"fmt"
+	"context"
 	"io"
 	"io/ioutil"
 	"math/rand"
@@ -32,6 +31,9 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+
+	k8errors "github.com/thekubeworld/k8devel/pkg/errors"
+	"github.com/thekubeworld/k8devel/pkg/logger"
 )
 
 // DetectReclaimPolicy is a helper for users to specify the reclaim policy
@@ -52,7 +54,7 @@ func DetectReclaimPolicy(reclaimPolicy string) (v1.PersistentVolumeReclaimPolicy
 	case "recycle":
 		return v1.PersistentVolumeReclaimRecycle, nil
 	}
-	return "", errors.New("unknown reclaim policy type")
+	return "", k8errors.New(k8errors.ErrInvalidSpec, "detect reclaim policy", "unknown reclaim policy type %q", reclaimPolicy)
 }
 
 // DetectConcurrencyPolicy is a helper for users
@@ -73,7 +75,7 @@ func DetectConcurrencyPolicy(currencyPolicy string) (batchv1.ConcurrencyPolicy,
 	case "replace":
 		return batchv1.ReplaceConcurrent, nil
 	}
-	return "", errors.New("unknown concurrency policy")
+	return "", k8errors.New(k8errors.ErrInvalidSpec, "detect concurrency policy", "unknown concurrency policy %q", currencyPolicy)
 }
 
 // LimitType is a helper for users to specify the limit type
@@ -95,7 +97,7 @@ func DetectLimitType(limitType string) (v1.LimitType, error) {
 	case "persistentvolumeclaim":
 		return v1.LimitTypePersistentVolumeClaim, nil
 	}
-	return "", errors.New("unknown limit type")
+	return "", k8errors.New(k8errors.ErrInvalidSpec, "detect limit type", "unknown limit type %q", limitType)
 }
 
 // DetectVolumeAccessModes is a helper for users
@@ -124,7 +126,7 @@ func DetectVolumeAccessModes(access string) ([]v1.PersistentVolumeAccessMode, er
 	case "readwriteonce", "rwo":
 		return []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}, nil
 	}
-	return nil, errors.New("unknown access mode")
+	return nil, k8errors.New(k8errors.ErrInvalidSpec, "detect access mode", "unknown access mode %q", access)
 }
 
 // DetectVolumeMode is a helper for users
@@ -147,7 +149,7 @@ func DetectVolumeMode(mode string) (v1.PersistentVolumeMode, error) {
 	case "persistentvolumefilesystem", "filesystem":
 		return v1.PersistentVolumeFilesystem, nil
 	}
-	return "", errors.New("unknown volume mode")
+	return "", k8errors.New(k8errors.ErrInvalidSpec, "detect volume mode", "unknown volume mode %q", mode)
 }
 
 // DetectContainerPortProtocol is a helper for users
@@ -167,7 +169,7 @@ func DetectContainerPortProtocol(protocol string) (v1.Protocol, error) {
 	case "udp":
 		return v1.ProtocolUDP, nil
 	}
-	return "", errors.New("unknown protocol")
+	return "", k8errors.New(k8errors.ErrInvalidSpec, "detect protocol", "unknown protocol %q", protocol)
 }
 
 // DetectImagePullPolicy is a helper for users to use more
@@ -190,7 +192,7 @@ func DetectImagePullPolicy(pullpolicy string) (v1.PullPolicy, error) {
 	case "ifnotpresent":
 		return v1.PullIfNotPresent, nil
 	}
-	return "", errors.New("unknown image pull policy")
+	return "", k8errors.New(k8errors.ErrInvalidSpec, "detect image pull policy", "unknown image pull policy %q", pullpolicy)
 }
 
 // DetectContainerRestartPolicy is a helper for users to more
@@ -213,7 +215,7 @@ func DetectContainerRestartPolicy(policy string) (v1.RestartPolicy, error) {
 	case "always":
 		return v1.RestartPolicyAlways, nil
 	}
-	return "", errors.New("unknown restart policy")
+	return "", k8errors.New(k8errors.ErrInvalidSpec, "detect restart policy", "unknown restart policy %q", policy)
 }
 
 // CompareFiles will compare two files, byte by byte
@@ -249,13 +251,16 @@ func CompareFiles(fileone string, filetwo string) (bool, error) {
 //   Returns:
 //      bytes from the file or error
 func DiffCommand(fileone string, filetwo string) ([]byte, error) {
-	fmt.Printf("Diffing %s and %s\n", fileone, filetwo)
+	logger.Default().Log(logger.LevelInfo, "Diffing files",
+		"fileone", fileone,
+		"filetwo", filetwo)
 	path, err := exec.LookPath("diff")
 	if err != nil {
 		return nil, err
 	}
 	var out bytes.Buffer
-	fmt.Printf("%s -r -u -N %s %s\n", path, fileone, filetwo)
+	logger.Default().Log(logger.LevelDebug, "Executing diff",
+		"command", path+" -r -u -N "+fileone+" "+filetwo)
 	cmd := exec.Command(path,
 		"-r",
 		"-u",
@@ -292,11 +297,30 @@ func CreateTempFile(dirname string, filename string) (*os.File, error) {
 // DownloadFile will download a file specified as temporary file
 //
 // Args:
+//    ctx - context for cancellation and deadline
+//    url - url to be download
+//
+//   Returns:
+//      path as string or error
+func DownloadFile(ctx context.Context, url string) (string, error) {
+	return DownloadFileWithClient(ctx, http.DefaultClient, url)
+}
+
+// DownloadFileWithClient will download a file specified as temporary
+// file using the http.Client provided
+//
+// Args:
+//    ctx - context for cancellation and deadline
+//    httpClient - http.Client, nil means http.DefaultClient
 //    url - url to be download
 //
 //   Returns:
 //      path as string or error
-func DownloadFile(url string) (string, error) {
+func DownloadFileWithClient(ctx context.Context, httpClient *http.Client, url string) (string, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
 	out, err := CreateTempFile(os.TempDir(), "downloadedfile")
 	if err != nil {
 		return "", err
@@ -304,7 +328,11 @@ func DownloadFile(url string) (string, error) {
 	defer out.Close()
 
 	// Get the data
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -336,7 +364,7 @@ func GenerateRandomString(numberOfChars int, modeString string) (string, error)
 	} else if modeString == "all" {
 		letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 	} else {
-		return "", errors.New("modeString must be lower or all")
+		return "", k8errors.New(k8errors.ErrInvalidSpec, "generate random string", "modeString must be lower or all")
 	}
 
 	result := make([]rune, numberOfChars)